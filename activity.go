@@ -0,0 +1,228 @@
+package main
+
+import (
+    "strconv"
+    "time"
+)
+
+// Single entry in the activity log of a note
+type Activity struct {
+    Time time.Time `json:"time"`
+    Author string `json:"author"`
+    Action string `json:"action"`
+}
+
+// Activity entry together with the note it belongs to
+type NoteActivity struct {
+    Note *Note
+    Activity *Activity
+}
+
+// Returns a copy of the note that does not share any slices with the original
+func copyNote(note *Note) (Note) {
+    ret := *note
+    ret.Tags = append([]string(nil), note.Tags...)
+    ret.Watchers = append([]string(nil), note.Watchers...)
+    ret.Activity = nil
+    ret.Comments = append([]Comment(nil), note.Comments...)
+    ret.Attachments = append([]Attachment(nil), note.Attachments...)
+    ret.DependsOn = append([]uint(nil), note.DependsOn...)
+    ret.Reminders = append([]Reminder(nil), note.Reminders...)
+    ret.TimeEntries = append([]TimeEntry(nil), note.TimeEntries...)
+    return ret
+}
+
+// Returns human readable descriptions of the changes made to the note
+// since old version of it
+func diffNotes(old *Note, note *Note, dueFormat string) ([]string) {
+    var ret []string
+
+    if old.GetTitle() != note.GetTitle() {
+        ret = append(ret, "changed title from \"" + old.GetTitle() + "\" to \"" + note.GetTitle() + "\"")
+    } else if old.Content != note.Content {
+        ret = append(ret, "edited content")
+    }
+
+    if old.Priority != note.Priority {
+        ret = append(ret, "priority " + strconv.Itoa(int(old.Priority)) + " → " + strconv.Itoa(int(note.Priority)))
+    }
+
+    if old.Estimate != note.Estimate {
+        if old.Estimate.IsZero() {
+            ret = append(ret, "estimate set to " + note.Estimate.String())
+        } else if note.Estimate.IsZero() {
+            ret = append(ret, "estimate removed")
+        } else {
+            ret = append(ret, "estimate changed from " + old.Estimate.String() + " to " + note.Estimate.String())
+        }
+    }
+
+    if !old.Due.Equal(note.Due) {
+        if old.Due.IsZero() {
+            ret = append(ret, "due set to " + note.Due.Format(dueFormat))
+        } else if note.Due.IsZero() {
+            ret = append(ret, "due removed")
+        } else {
+            ret = append(ret, "due moved from " + old.Due.Format(dueFormat) + " to " + note.Due.Format(dueFormat))
+        }
+    }
+
+    if !old.Scheduled.Equal(note.Scheduled) {
+        if old.Scheduled.IsZero() {
+            ret = append(ret, "deferred until " + note.Scheduled.Format(dueFormat))
+        } else if note.Scheduled.IsZero() {
+            ret = append(ret, "start date removed")
+        } else {
+            ret = append(ret, "start date moved from " + old.Scheduled.Format(dueFormat) + " to " + note.Scheduled.Format(dueFormat))
+        }
+    }
+
+    for _, tag := range note.Tags {
+        if !old.HasExactTag(tag) {
+            ret = append(ret, "added tag \"" + tag + "\"")
+        }
+    }
+
+    for _, tag := range old.Tags {
+        if !note.HasExactTag(tag) {
+            ret = append(ret, "removed tag \"" + tag + "\"")
+        }
+    }
+
+    if old.Assignee != note.Assignee {
+        if len(note.Assignee) == 0 {
+            ret = append(ret, "unassigned from " + old.Assignee)
+        } else {
+            ret = append(ret, "assigned to " + note.Assignee)
+        }
+    }
+
+    for _, who := range note.Watchers {
+        if !old.HasWatcher(who) {
+            ret = append(ret, "added watcher " + who)
+        }
+    }
+
+    for _, who := range old.Watchers {
+        if !note.HasWatcher(who) {
+            ret = append(ret, "removed watcher " + who)
+        }
+    }
+
+    if len(note.Comments) > len(old.Comments) {
+        ret = append(ret, "added comment")
+    }
+
+    for _, a := range note.Attachments {
+        if !hasAttachment(old, a.FileId) {
+            ret = append(ret, "attached file " + a.Name)
+        }
+    }
+
+    for _, a := range old.Attachments {
+        if !hasAttachment(note, a.FileId) {
+            ret = append(ret, "removed attachment " + a.Name)
+        }
+    }
+
+    for _, id := range note.DependsOn {
+        if !old.DependsOnNote(id) {
+            ret = append(ret, "added dependency on #" + strconv.Itoa(int(id)))
+        }
+    }
+
+    for _, id := range old.DependsOn {
+        if !note.DependsOnNote(id) {
+            ret = append(ret, "removed dependency on #" + strconv.Itoa(int(id)))
+        }
+    }
+
+    if old.Parent != note.Parent {
+        if note.Parent == 0 {
+            ret = append(ret, "moved to top level")
+        } else {
+            ret = append(ret, "moved under #" + strconv.Itoa(int(note.Parent)))
+        }
+    }
+
+    ret = append(ret, diffReminders(old, note, dueFormat)...)
+
+    for i, entry := range note.TimeEntries {
+        if i >= len(old.TimeEntries) {
+            if entry.IsRunning() {
+                ret = append(ret, "started timer")
+            } else {
+                ret = append(ret, "logged " + FormatDuration(entry.GetDuration().Round(time.Minute)))
+            }
+        } else if old.TimeEntries[i].IsRunning() && !entry.IsRunning() {
+            ret = append(ret, "logged " + FormatDuration(entry.GetDuration().Round(time.Minute)))
+        }
+    }
+
+    if old.Status != note.Status && len(old.Status) > 0 && len(note.Status) > 0 {
+        ret = append(ret, "changed status from " + old.Status + " to " + note.Status)
+    } else {
+        // Notes saved before statuses were introduced get their first status
+        if old.Status != note.Status && len(note.Status) > 0 {
+            ret = append(ret, "status set to " + note.Status)
+        }
+
+        if old.Done != note.Done {
+            if note.Done {
+                ret = append(ret, "marked done")
+            } else {
+                ret = append(ret, "marked not done")
+            }
+        }
+    }
+
+    return ret
+}
+
+// Delivering reminders is not logged, only adding, removing and
+// rescheduling them
+func diffReminders(old *Note, note *Note, dueFormat string) ([]string) {
+    var ret []string
+    timeFormat := dueFormat + " 15:04"
+
+    if len(old.Reminders) == len(note.Reminders) {
+        for i, _ := range note.Reminders {
+            before := old.Reminders[i].Describe(timeFormat)
+            after := note.Reminders[i].Describe(timeFormat)
+            if before != after {
+                ret = append(ret, "rescheduled reminder from " + before + " to " + after)
+            }
+        }
+        return ret
+    }
+
+    var oldReminders []string
+    for _, r := range old.Reminders {
+        oldReminders = append(oldReminders, r.Describe(timeFormat))
+    }
+    var newReminders []string
+    for _, r := range note.Reminders {
+        newReminders = append(newReminders, r.Describe(timeFormat))
+    }
+
+    for _, r := range newReminders {
+        if !containsString(oldReminders, r) {
+            ret = append(ret, "added reminder " + r)
+        }
+    }
+    for _, r := range oldReminders {
+        if !containsString(newReminders, r) {
+            ret = append(ret, "removed reminder " + r)
+        }
+    }
+    return ret
+}
+
+func hasAttachment(note *Note, fileId string) (bool) {
+    for _, a := range note.Attachments {
+        if a.FileId == fileId {
+            return true
+        }
+    }
+    return false
+}
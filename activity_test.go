@@ -0,0 +1,67 @@
+package main
+
+import (
+    "reflect"
+    "testing"
+    "time"
+)
+
+func TestDiffNotes(t *testing.T) {
+    due := time.Date(2026, 10, 14, 0, 0, 0, 0, time.Local)
+    base := Note{Id: 1, Content: "Title\nBody", Priority: 3, Status: "open", Tags: []string{"work"}}
+
+    tests := []struct {
+        name string
+        change func(note *Note)
+        expected []string
+    }{
+        {"nothing", func(note *Note) {}, nil},
+        {"title", func(note *Note) { note.Content = "New\nBody" }, []string{"changed title from \"Title\" to \"New\""}},
+        {"content", func(note *Note) { note.Content = "Title\nMore" }, []string{"edited content"}},
+        {"priority", func(note *Note) { note.Priority = 5 }, []string{"priority 3 → 5"}},
+        {"tags", func(note *Note) { note.Tags = []string{"home"} }, []string{"added tag \"home\"", "removed tag \"work\""}},
+        {"due", func(note *Note) { note.Due = due }, []string{"due set to 14.10.2026"}},
+        {"assignee", func(note *Note) { note.Assignee = "bob" }, []string{"assigned to bob"}},
+        {"parent", func(note *Note) { note.Parent = 4 }, []string{"moved under #4"}},
+        {"status", func(note *Note) { note.Status = "in progress" }, []string{"changed status from open to in progress"}},
+        {"done", func(note *Note) { note.Status = "done"; note.Done = true }, []string{"changed status from open to done"}},
+    }
+
+    for _, test := range tests {
+        old := copyNote(&base)
+        note := copyNote(&base)
+        test.change(&note)
+        actions := diffNotes(&old, &note, "02.01.2006")
+        if !reflect.DeepEqual(actions, test.expected) {
+            t.Errorf("%v: diffNotes() = %q, want %q", test.name, actions, test.expected)
+        }
+    }
+
+    // Notes saved before statuses were introduced have no status
+    old := Note{Id: 1}
+    note := Note{Id: 1, Status: "done", Done: true}
+    expected := []string{"status set to done", "marked done"}
+    if actions := diffNotes(&old, &note, "02.01.2006"); !reflect.DeepEqual(actions, expected) {
+        t.Errorf("diffNotes() without status = %q, want %q", actions, expected)
+    }
+}
+
+func TestRecordActivityReusedId(t *testing.T) {
+    n := newTestNotes(Note{Id: 1, Content: "Old", Created: time.Now().Add(-time.Hour)})
+    n.config.UserName = "alice"
+
+    n.DeleteNote(1)
+    id := n.AddNote(Note{Content: "New"})
+    if id != 1 {
+        t.Fatalf("AddNote() = %v, want reused id 1", id)
+    }
+
+    n.recordActivity()
+    var actions []string
+    for _, a := range n.FindNote(1).Activity {
+        actions = append(actions, a.Action)
+    }
+    if !reflect.DeepEqual(actions, []string{"created"}) {
+        t.Errorf("Activity of note with reused id = %q, want [created]", actions)
+    }
+}
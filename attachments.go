@@ -0,0 +1,170 @@
+package main
+
+import (
+    "errors"
+    "io"
+    "log"
+    "net/http"
+    "os"
+    "path/filepath"
+    "strconv"
+    "time"
+
+    "google.golang.org/api/drive/v3"
+    "google.golang.org/api/googleapi"
+)
+
+// File attached to a note and stored in Google Drive
+type Attachment struct {
+    FileId string `json:"file_id"`
+    Name string `json:"name"`
+    Size int64 `json:"size"`
+    Created time.Time `json:"created"`
+}
+
+// Uploads given file to Drive and attaches it to the note
+func (n *Notes) AttachFile(note *Note, path string) (*Attachment, error) {
+    f, err := os.Open(path)
+    if err != nil {
+        return nil, err
+    }
+    defer f.Close()
+
+    name := filepath.Base(path)
+    // Prefix the file so that attachments can't be mixed with the notes file
+    new_file := &drive.File{
+        Name: "attachment-" + strconv.Itoa(int(note.Id)) + "-" + name,
+        Parents: []string{n.getParentFolder()},
+    }
+    create := n.gdrive.Files.Create(new_file).Fields("id, size")
+    if n.config.IsShared() {
+        create.SupportsAllDrives(true)
+    }
+
+    file, err := create.Media(f).Do()
+    if err != nil {
+        return nil, err
+    }
+
+    note.Attachments = append(note.Attachments, Attachment{
+        FileId: file.Id,
+        Name: name,
+        Size: file.Size,
+        Created: time.Now(),
+    })
+    return &note.Attachments[len(note.Attachments)-1], nil
+}
+
+// Returns the name of the attachment that is safe to use as a file name.
+// Names are stored in shared notes so they can not be trusted.
+func (a *Attachment) GetFileName() (string, error) {
+    name := filepath.Base(a.Name)
+    if name == "." || name == ".." || name == string(filepath.Separator) || len(name) == 0 {
+        return "", errors.New("Invalid attachment name " + a.Name)
+    }
+    return name, nil
+}
+
+// Downloads attachment of the note to given directory and returns the path
+// of the downloaded file. Existing files are not overwritten.
+func (n *Notes) FetchAttachment(attachment *Attachment, dir string) (string, error) {
+    name, err := attachment.GetFileName()
+    if err != nil {
+        return "", err
+    }
+
+    path := filepath.Join(dir, name)
+    f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0660)
+    if err != nil {
+        if os.IsExist(err) {
+            return "", errors.New("File " + path + " already exists")
+        }
+        return "", err
+    }
+
+    err = n.downloadAttachment(attachment, f)
+    cerr := f.Close()
+    if err == nil {
+        err = cerr
+    }
+    if err != nil {
+        // Partially downloaded file is removed
+        os.Remove(path)
+        return "", err
+    }
+    return path, nil
+}
+
+func (n *Notes) downloadAttachment(attachment *Attachment, f *os.File) (error) {
+    get := n.gdrive.Files.Get(attachment.FileId)
+    if n.config.IsShared() {
+        get.SupportsAllDrives(true)
+    }
+
+    res, err := get.Download()
+    if err != nil {
+        return err
+    }
+    defer res.Body.Close()
+
+    _, err = io.Copy(f, res.Body)
+    if err != nil {
+        return err
+    }
+    return f.Sync()
+}
+
+// Removes attachment with given index from the note. The file is removed
+// from Drive when notes are saved.
+func (n *Notes) DetachFile(note *Note, idx int) (*Attachment, error) {
+    if idx < 0 || idx >= len(note.Attachments) {
+        return nil, errors.New("Could not find attachment with given number")
+    }
+
+    attachment := note.Attachments[idx]
+    note.Attachments = append(note.Attachments[:idx], note.Attachments[idx+1:]...)
+    n.removedFiles = append(n.removedFiles, attachment.FileId)
+    return &attachment, nil
+}
+
+// Returns attachment of the note by its number starting from 1
+func (n *Note) GetAttachment(num string) (*Attachment, int, error) {
+    i, err := strconv.Atoi(num)
+    if err != nil || i < 1 || i > len(n.Attachments) {
+        return nil, -1, errors.New("Could not find attachment with given number")
+    }
+    return &n.Attachments[i-1], i-1, nil
+}
+
+// Removes files of deleted attachments from Drive. Files already removed
+// by someone else are ignored. Failures do not fail saving the notes so
+// they are only logged. Returns the files that should be removed again with
+// the next save.
+func (n *Notes) deleteFiles(ids []string) ([]string) {
+    var failed []string
+    for _, id := range ids {
+        del := n.gdrive.Files.Delete(id)
+        if n.config.IsShared() {
+            del.SupportsAllDrives(true)
+        }
+
+        err := del.Do()
+        if err == nil {
+            continue
+        }
+
+        gerr, ok := err.(*googleapi.Error)
+        if ok && gerr.Code == http.StatusNotFound {
+            continue
+        }
+
+        // Files that can not be removed by the user are left to Drive
+        if ok && gerr.Code >= 400 && gerr.Code < 500 && gerr.Code != http.StatusTooManyRequests {
+            log.Printf("Could not remove attachment file %v: %v", id, err)
+            continue
+        }
+        log.Printf("Could not remove attachment file %v, trying again with next save: %v", id, err)
+        failed = append(failed, id)
+    }
+    return failed
+}
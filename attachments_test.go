@@ -0,0 +1,85 @@
+package main
+
+import (
+    "io/ioutil"
+    "log"
+    "net/http"
+    "net/http/httptest"
+    "os"
+    "reflect"
+    "strings"
+    "testing"
+
+    "google.golang.org/api/drive/v3"
+)
+
+func TestAttachmentGetFileName(t *testing.T) {
+    tests := []struct {
+        name string
+        expected string
+        err bool
+    }{
+        {"report.pdf", "report.pdf", false},
+        {"../../.bashrc", ".bashrc", false},
+        {"/etc/passwd", "passwd", false},
+        {"dir/", "dir", false},
+        {"", "", true},
+        {".", "", true},
+        {"..", "", true},
+        {"/", "", true},
+    }
+
+    for _, test := range tests {
+        attachment := Attachment{Name: test.name}
+        name, err := attachment.GetFileName()
+        if test.err {
+            if err == nil {
+                t.Errorf("GetFileName() of %q should fail", test.name)
+            }
+            continue
+        }
+        if err != nil {
+            t.Errorf("GetFileName() of %q failed: %v", test.name, err)
+            continue
+        }
+        if name != test.expected {
+            t.Errorf("GetFileName() of %q = %q, want %q", test.name, name, test.expected)
+        }
+    }
+}
+
+func TestDeleteFiles(t *testing.T) {
+    // Drive answers deletes with the status code given as the file id
+    server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        code := http.StatusNoContent
+        switch(r.URL.Path[strings.LastIndex(r.URL.Path, "/") + 1:]) {
+            case "missing":
+                code = http.StatusNotFound
+                break
+            case "forbidden":
+                code = http.StatusForbidden
+                break
+            case "unavailable":
+                code = http.StatusServiceUnavailable
+                break
+        }
+        w.WriteHeader(code)
+    }))
+    defer server.Close()
+
+    srv, err := drive.New(server.Client())
+    if err != nil {
+        t.Fatal(err)
+    }
+    srv.BasePath = server.URL + "/"
+
+    log.SetOutput(ioutil.Discard)
+    defer log.SetOutput(os.Stderr)
+
+    n := newTestNotes()
+    n.gdrive = srv
+    failed := n.deleteFiles([]string{"deleted", "missing", "forbidden", "unavailable"})
+    if !reflect.DeepEqual(failed, []string{"unavailable"}) {
+        t.Errorf("deleteFiles() = %v, want only unavailable to be retried", failed)
+    }
+}
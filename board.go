@@ -0,0 +1,308 @@
+package main
+
+import (
+    "fmt"
+    "strconv"
+    "strings"
+
+    "github.com/jroimartin/gocui"
+    "github.com/fatih/color"
+)
+
+const (
+    BOARD_VIEW = "board"
+)
+
+var boardCategories = []string{"status", "prio", "due"}
+
+// Returns the view that is used for browsing notes
+func (n *NotesGui) mainView() (string) {
+    if n.templatePickerShown {
+        return TEMPLATE_VIEW
+    }
+    if n.calendarShown {
+        return CALENDAR_VIEW
+    }
+    if n.boardMode {
+        return BOARD_VIEW
+    }
+    return LIST_VIEW
+}
+
+func (n *NotesGui) setBoardKeybindings(g *gocui.Gui) (error) {
+    bindings := map[interface{}]func(*gocui.Gui, *gocui.View) error {
+        'j': n.boardDown,
+        'k': n.boardUp,
+        'h': n.boardLeft,
+        'l': n.boardRight,
+        'H': n.boardMoveLeft,
+        'L': n.boardMoveRight,
+        'e': n.editNote,
+        'a': n.addNote,
+        'm': n.boardMark,
+        's': n.cycleStatus,
+        't': n.toggleTimer,
+        'B': n.toggleBoard,
+        '/': n.startSearch,
+        ':': n.startCommand,
+        gocui.KeyTab: n.toggleBoardCategory,
+        gocui.KeySpace: n.toggleDone,
+    }
+
+    for key, f := range bindings {
+        err := g.SetKeybinding(BOARD_VIEW, key, gocui.ModNone, f)
+        if err != nil {
+            return err
+        }
+    }
+    return nil
+}
+
+func (n *NotesGui) toggleBoard(g *gocui.Gui, v *gocui.View) error {
+    n.boardMode = !n.boardMode
+    if len(n.boardCategory) == 0 {
+        n.boardCategory = boardCategories[0]
+    }
+    n.visualMode = false
+    n.updateShownNotes()
+
+    err := n.layout(g)
+    if err != nil {
+        return err
+    }
+    return n.update(g)
+}
+
+func (n *NotesGui) toggleBoardCategory(g *gocui.Gui, v *gocui.View) error {
+    idx := 0
+    for i, cat := range boardCategories {
+        if cat == n.boardCategory {
+            idx = i + 1
+            break
+        }
+    }
+    n.boardCategory = boardCategories[idx % len(boardCategories)]
+    return n.update(g)
+}
+
+// Returns the columns of the board and their notes
+func (n *NotesGui) getBoardColumns() ([]string, [][]*Note) {
+    cat, keys := n.Notes.CategorizeNotes(n.boardCategory, n.shownNotes)
+    columns := n.Notes.GetCategoryKeys(n.boardCategory, keys)
+
+    notes := make([][]*Note, len(columns))
+    for i, key := range keys {
+        for j, column := range columns {
+            if column == key {
+                notes[j] = cat[i]
+                break
+            }
+        }
+    }
+    return columns, notes
+}
+
+// Returns the column and row of the selected note in the board
+func (n *NotesGui) getBoardPosition(notes [][]*Note) (int, int) {
+    if n.selectedNote == nil {
+        return -1, -1
+    }
+
+    for col, _ := range notes {
+        for row, note := range notes[col] {
+            if note.Id == n.selectedNote.Id {
+                return col, row
+            }
+        }
+    }
+    return -1, -1
+}
+
+func (n *NotesGui) selectBoardNote(g *gocui.Gui, note *Note) error {
+    for i, shown := range n.shownNotes {
+        if shown.Id == note.Id {
+            n.idx = i
+            break
+        }
+    }
+    n.updateShownNotes()
+    return n.update(g)
+}
+
+func (n *NotesGui) boardVertical(g *gocui.Gui, delta int) error {
+    _, notes := n.getBoardColumns()
+    col, row := n.getBoardPosition(notes)
+    if col < 0 {
+        return nil
+    }
+
+    row += delta
+    if row < 0 {
+        row = len(notes[col]) - 1
+    } else if row >= len(notes[col]) {
+        row = 0
+    }
+    return n.selectBoardNote(g, notes[col][row])
+}
+
+func (n *NotesGui) boardMark(g *gocui.Gui, v *gocui.View) error {
+    if n.selectedNote == nil {
+        return nil
+    }
+
+    if n.marks[n.selectedNote.Id] {
+        delete(n.marks, n.selectedNote.Id)
+    } else {
+        n.marks[n.selectedNote.Id] = true
+    }
+    return n.boardVertical(g, 1)
+}
+
+func (n *NotesGui) boardDown(g *gocui.Gui, v *gocui.View) error {
+    return n.boardVertical(g, 1)
+}
+
+func (n *NotesGui) boardUp(g *gocui.Gui, v *gocui.View) error {
+    return n.boardVertical(g, -1)
+}
+
+// Moves the selection to the closest non-empty column in given direction
+func (n *NotesGui) boardHorizontal(g *gocui.Gui, delta int) error {
+    _, notes := n.getBoardColumns()
+    col, row := n.getBoardPosition(notes)
+    if col < 0 {
+        return nil
+    }
+
+    for i := col + delta; i >= 0 && i < len(notes); i += delta {
+        if len(notes[i]) == 0 {
+            continue
+        }
+        if row >= len(notes[i]) {
+            row = len(notes[i]) - 1
+        }
+        return n.selectBoardNote(g, notes[i][row])
+    }
+    return nil
+}
+
+func (n *NotesGui) boardLeft(g *gocui.Gui, v *gocui.View) error {
+    return n.boardHorizontal(g, -1)
+}
+
+func (n *NotesGui) boardRight(g *gocui.Gui, v *gocui.View) error {
+    return n.boardHorizontal(g, 1)
+}
+
+// Moves the selected or marked notes to the next column in given direction
+func (n *NotesGui) boardMove(g *gocui.Gui, delta int) error {
+    columns, notes := n.getBoardColumns()
+    col, _ := n.getBoardPosition(notes)
+    if col < 0 {
+        return nil
+    }
+
+    col += delta
+    if col < 0 || col >= len(columns) {
+        return nil
+    }
+
+    for _, note := range n.getTargetNotes() {
+        err := n.Notes.SetCategory(note, n.boardCategory, columns[col])
+        if err != nil {
+            n.statusString = err.Error()
+            return n.update(g)
+        }
+    }
+
+    n.clearMarks()
+    n.unsavedModifications = true
+    n.handleAsyncSave()
+    n.shownNotes = nil
+    n.updateShownNotes()
+    return n.update(g)
+}
+
+func (n *NotesGui) boardMoveLeft(g *gocui.Gui, v *gocui.View) error {
+    return n.boardMove(g, -1)
+}
+
+func (n *NotesGui) boardMoveRight(g *gocui.Gui, v *gocui.View) error {
+    return n.boardMove(g, 1)
+}
+
+// Pads or truncates the string to given width
+func fitString(str string, width int) (string) {
+    runes := []rune(str)
+    if len(runes) > width {
+        if width > 1 {
+            return string(runes[:width-1]) + "~"
+        }
+        return string(runes[:width])
+    }
+    return str + strings.Repeat(" ", width - len(runes))
+}
+
+func (n *NotesGui) updateBoardView(g *gocui.Gui) error {
+    v, err := g.View(BOARD_VIEW)
+    if err != nil {
+        return err
+    }
+
+    v.Clear()
+    v.Title = "Board"
+    if len(n.tagFilter) > 0 {
+        v.Title += " - " + n.tagFilter
+    }
+    if n.showMine {
+        v.Title += " (mine)"
+    }
+    if !n.dueFilter.IsZero() {
+        v.Title += " (due " + n.dueFilter.Format(n.Config.DueFormat) + ")"
+    }
+
+    columns, notes := n.getBoardColumns()
+    maxX, _ := v.Size()
+    width := maxX / len(columns)
+    if width < 4 {
+        width = 4
+    }
+
+    rows := 0
+    for i, column := range columns {
+        header := column + " (" + strconv.Itoa(len(notes[i])) + ")"
+        fmt.Fprint(v, color.New(color.Bold).Sprint(fitString(header, width)))
+        if len(notes[i]) > rows {
+            rows = len(notes[i])
+        }
+    }
+    fmt.Fprintln(v, "")
+    fmt.Fprintln(v, strings.Repeat("-", maxX))
+
+    for row := 0; row < rows; row++ {
+        for col, _ := range columns {
+            if row >= len(notes[col]) {
+                fmt.Fprint(v, strings.Repeat(" ", width))
+                continue
+            }
+
+            note := notes[col][row]
+            line := "#" + strconv.Itoa(int(note.Id)) + " " + note.GetTitle()
+            if n.marks[note.Id] {
+                line = "* " + line
+            }
+            line = fitString(line, width - 1) + " "
+
+            if n.selectedNote != nil && n.selectedNote.Id == note.Id {
+                line = color.New(color.Bold).Add(color.BgWhite).Add(color.FgBlack).Sprint(line)
+            } else if n.marks[note.Id] {
+                line = color.New(color.FgHiCyan).Sprint(line)
+            } else if !note.Done && n.Notes.IsBlocked(note) {
+                line = color.New(color.FgHiBlack).Sprint(line)
+            }
+            fmt.Fprint(v, line)
+        }
+        fmt.Fprintln(v, "")
+    }
+    return nil
+}
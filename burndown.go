@@ -0,0 +1,125 @@
+package main
+
+import (
+    "strings"
+    "time"
+)
+
+// Remaining open notes at the end of a day of the burndown
+type BurndownDay struct {
+    Day time.Time
+    Remaining int
+    Ideal float64
+    Future bool
+}
+
+const ISO_DATE_FORMAT = "2006-01-02"
+
+// Parses first or last day of the burndown. Dates are accepted in ISO format
+// in addition to the given format. Start is parsed like --since so that for
+// example "monday" and "14d" are in the past while the end is parsed like due
+// dates so they are in the future.
+func ParseBurndownDay(str string, format string, start bool) (time.Time, error) {
+    t, err := time.ParseInLocation(ISO_DATE_FORMAT, strings.Trim(str, " "), time.Local)
+    if err != nil && start {
+        t, err = ParseSince(str, format)
+    } else if err != nil {
+        t, err = ParseDate(str, format)
+    }
+    if err != nil {
+        return t, err
+    }
+    return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.Local), nil
+}
+
+// Returns whether the note was done at given time. Done and not done
+// transitions in the activity log are used when available, otherwise the
+// completion time of the note is used.
+func (n *Notes) isDoneAt(note *Note, t time.Time) (bool) {
+    found := false
+    done := false
+    for i, _ := range note.Activity {
+        activity := &note.Activity[i]
+        if activity.Time.After(t) {
+            break
+        }
+
+        switch(activity.Action) {
+            case "marked done":
+                found = true
+                done = true
+                continue
+            case "marked not done":
+                found = true
+                done = false
+                continue
+        }
+
+        if strings.HasPrefix(activity.Action, "changed status from ") {
+            parts := strings.SplitN(activity.Action, " to ", 2)
+            if len(parts) == 2 {
+                found = true
+                done = n.config.IsDoneStatus(parts[1])
+            }
+        }
+    }
+
+    if found {
+        return done
+    }
+    return note.Done && !n.GetCompletedTime(note).After(t)
+}
+
+// Returns number of open notes at the end of each day between the given
+// days and the number of open notes at the start of the first day. Ideal
+// line goes from the start to zero at the end of the last day. Done notes
+// without known completion time are skipped.
+func (n *Notes) GetBurndown(notes []*Note, from time.Time, to time.Time) ([]BurndownDay, int) {
+    var known []*Note
+    for _, note := range notes {
+        if !note.Done || !n.GetCompletedTime(note).IsZero() {
+            known = append(known, note)
+        }
+    }
+    notes = known
+
+    var ret []BurndownDay
+    from = RoundTimeToDay(from)
+    to = RoundTimeToDay(to)
+    now := time.Now()
+
+    start := 0
+    for _, note := range notes {
+        if !note.Created.After(from) && !n.isDoneAt(note, from) {
+            start++
+        }
+    }
+
+    days := 0
+    for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+        days++
+    }
+
+    i := 0
+    for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+        i++
+        entry := BurndownDay{Day: day, Ideal: float64(start) * float64(days - i) / float64(days)}
+        end := day.AddDate(0, 0, 1)
+        if day.After(now) {
+            entry.Future = true
+            ret = append(ret, entry)
+            continue
+        }
+        if end.After(now) {
+            end = now
+        }
+
+        for _, note := range notes {
+            if !note.Created.After(end) && !n.isDoneAt(note, end) {
+                entry.Remaining++
+            }
+        }
+        ret = append(ret, entry)
+    }
+    return ret, start
+}
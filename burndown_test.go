@@ -0,0 +1,102 @@
+package main
+
+import (
+    "reflect"
+    "testing"
+    "time"
+)
+
+func TestParseBurndownDay(t *testing.T) {
+    today := RoundTimeToDay(time.Now())
+
+    tests := []struct {
+        str string
+        start bool
+        expected time.Time
+        err bool
+    }{
+        {"2026-10-01", true, time.Date(2026, 10, 1, 0, 0, 0, 0, time.Local), false},
+        {"2026-10-01", false, time.Date(2026, 10, 1, 0, 0, 0, 0, time.Local), false},
+        {"01.10.2026", false, time.Date(2026, 10, 1, 0, 0, 0, 0, time.Local), false},
+        {"today", true, today, false},
+        {"today", false, today, false},
+        {"14d", true, today.AddDate(0, 0, -14), false},
+        {"3d", false, today.AddDate(0, 0, 3), false},
+        {"tomorrow", false, today.AddDate(0, 0, 1), false},
+        {"2026-13-01", true, time.Time{}, true},
+        {"someday", false, time.Time{}, true},
+    }
+
+    for _, test := range tests {
+        day, err := ParseBurndownDay(test.str, "02.01.2006", test.start)
+        if test.err {
+            if err == nil {
+                t.Errorf("ParseBurndownDay(%q, %v) should fail", test.str, test.start)
+            }
+            continue
+        }
+        if err != nil {
+            t.Errorf("ParseBurndownDay(%q, %v) failed: %v", test.str, test.start, err)
+            continue
+        }
+        if !day.Equal(test.expected) {
+            t.Errorf("ParseBurndownDay(%q, %v) = %v, want %v", test.str, test.start, day, test.expected)
+        }
+    }
+
+    // Weekdays are in the past for the start and in the future for the end
+    start, _ := ParseBurndownDay("monday", "02.01.2006", true)
+    end, _ := ParseBurndownDay("monday", "02.01.2006", false)
+    if start.After(today) || !end.After(today) {
+        t.Errorf("ParseBurndownDay(monday) = %v - %v, want start in the past and end in the future", start, end)
+    }
+}
+
+func TestGetBurndown(t *testing.T) {
+    today := RoundTimeToDay(time.Now())
+    day := func(offset int, hour int) (time.Time) {
+        return today.AddDate(0, 0, offset).Add(time.Duration(hour) * time.Hour)
+    }
+
+    n := newTestNotes(
+        Note{Id: 1, Created: day(-10, 0)},
+        Note{Id: 2, Created: day(-10, 0), Done: true, CompletedAt: day(-3, 12)},
+        Note{Id: 3, Created: day(-2, 12)},
+        // Completion time is not known
+        Note{Id: 4, Created: day(-10, 0), Done: true},
+        Note{Id: 5, Created: day(-10, 0), Activity: []Activity{
+            {Time: day(-4, 10), Action: "marked done"},
+            {Time: day(-2, 10), Action: "marked not done"},
+        }},
+        Note{Id: 6, Created: day(-10, 0), Done: true, Activity: []Activity{
+            {Time: day(-5, 10), Action: "changed status from open to done"},
+        }},
+    )
+
+    days, start := n.GetBurndown(n.GetNotes(), day(-4, 0), day(-1, 0))
+    if start != 3 {
+        t.Errorf("GetBurndown() start = %v, want 3", start)
+    }
+
+    var remaining []int
+    var ideal []float64
+    for _, d := range days {
+        remaining = append(remaining, d.Remaining)
+        ideal = append(ideal, d.Ideal)
+        if d.Future {
+            t.Errorf("GetBurndown() day %v should not be in the future", d.Day)
+        }
+    }
+
+    if !reflect.DeepEqual(remaining, []int{2, 1, 3, 3}) {
+        t.Errorf("GetBurndown() remaining = %v, want [2 1 3 3]", remaining)
+    }
+    if !reflect.DeepEqual(ideal, []float64{2.25, 1.5, 0.75, 0}) {
+        t.Errorf("GetBurndown() ideal = %v, want [2.25 1.5 0.75 0]", ideal)
+    }
+
+    days, _ = n.GetBurndown(n.GetNotes(), day(-1, 0), day(1, 0))
+    if len(days) != 3 || days[0].Future || days[1].Future || !days[2].Future {
+        t.Errorf("GetBurndown() should mark only days after today to be in the future")
+    }
+}
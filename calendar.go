@@ -0,0 +1,192 @@
+package main
+
+import (
+    "fmt"
+    "io"
+    "strconv"
+    "strings"
+    "time"
+
+    "github.com/jroimartin/gocui"
+    "github.com/fatih/color"
+)
+
+var weekdayHeader = []string{"Mo", "Tu", "We", "Th", "Fr", "Sa", "Su"}
+
+func IsSameDay(a time.Time, b time.Time) (bool) {
+    return a.Year() == b.Year() && a.Month() == b.Month() && a.Day() == b.Day()
+}
+
+// Returns notes that are due on given day
+func (n *Notes) FilterNotesByDueDay(day time.Time, notes []*Note) ([]*Note) {
+    var ret []*Note
+    for _, note := range notes {
+        if !note.Due.IsZero() && IsSameDay(note.Due, day) {
+            ret = append(ret, note)
+        }
+    }
+    return ret
+}
+
+// Returns not done notes that are due before given day
+func (n *Notes) GetOverdueNotes(day time.Time) ([]*Note) {
+    var ret []*Note
+    for _, note := range n.FilterDoneNotes(n.GetNotes()) {
+        if !note.Due.IsZero() && RoundTimeToDay(note.Due).Before(day) {
+            ret = append(ret, note)
+        }
+    }
+    return ret
+}
+
+// Returns the highest priority note of the given notes
+func getHighestPriorityNote(notes []*Note) (*Note) {
+    var ret *Note
+    for _, note := range notes {
+        if ret == nil || note.Priority > ret.Priority {
+            ret = note
+        }
+    }
+    return ret
+}
+
+// Writes month grid with the days colored by the highest priority of the not
+// done notes due on the day. Number of due notes is added after the day when
+// counts are shown.
+func (n *Notes) WriteMonth(w io.Writer, month time.Time, selected time.Time, showCounts bool, useColor bool) {
+    first := time.Date(month.Year(), month.Month(), 1, 0, 0, 0, 0, time.UTC)
+    cellSize := 4
+    if showCounts {
+        cellSize = 7
+    }
+
+    for _, day := range weekdayHeader {
+        fmt.Fprint(w, fitString(day, cellSize))
+    }
+    fmt.Fprintln(w, "")
+
+    // Weeks start from monday
+    offset := (int(first.Weekday()) + 6) % 7
+    fmt.Fprint(w, strings.Repeat(" ", offset * cellSize))
+
+    today := GetToday()
+    open := n.FilterDoneNotes(n.GetNotes())
+    for day := first; day.Month() == first.Month(); day = day.AddDate(0, 0, 1) {
+        due := n.FilterNotesByDueDay(day, open)
+        cell := fmt.Sprintf("%2d", day.Day())
+        if showCounts && len(due) > 0 {
+            cell += "(" + strconv.Itoa(len(due)) + ")"
+        }
+        cell = fitString(cell, cellSize)
+
+        c := color.New()
+        if len(due) > 0 {
+            c = GetPriorityColor(getHighestPriorityNote(due))
+        }
+        if IsSameDay(day, today) {
+            c.Add(color.Underline)
+        }
+        if !selected.IsZero() && IsSameDay(day, selected) {
+            c.Add(color.ReverseVideo)
+        }
+        if !useColor {
+            c.DisableColor()
+        }
+
+        // Only the day is colored so that the cells stay separated
+        trimmed := strings.TrimRight(cell, " ")
+        fmt.Fprint(w, c.Sprint(trimmed) + cell[len(trimmed):])
+
+        if day.Weekday() == time.Sunday {
+            fmt.Fprintln(w, "")
+        }
+    }
+
+    last := first.AddDate(0, 1, -1)
+    if last.Weekday() != time.Sunday {
+        fmt.Fprintln(w, "")
+    }
+}
+
+const (
+    CALENDAR_VIEW = "calendar"
+)
+
+func (n *NotesGui) setCalendarKeybindings(g *gocui.Gui) (error) {
+    bindings := map[interface{}]func(*gocui.Gui, *gocui.View) error {
+        'h': n.calendarMove(0, -1),
+        'l': n.calendarMove(0, 1),
+        'k': n.calendarMove(0, -7),
+        'j': n.calendarMove(0, 7),
+        'H': n.calendarMove(-1, 0),
+        'L': n.calendarMove(1, 0),
+        'C': n.closeCalendar,
+        gocui.KeyEnter: n.selectCalendarDay,
+    }
+
+    for key, f := range bindings {
+        err := g.SetKeybinding(CALENDAR_VIEW, key, gocui.ModNone, f)
+        if err != nil {
+            return err
+        }
+    }
+    return nil
+}
+
+// Shows the calendar and filters the notes by the selected day
+func (n *NotesGui) openCalendar(g *gocui.Gui, v *gocui.View) error {
+    n.calendarShown = true
+    n.dueFilter = GetToday()
+    n.updateShownNotes()
+
+    err := n.layout(g)
+    if err != nil {
+        return err
+    }
+    return n.update(g)
+}
+
+// Hides the calendar and clears the day filter
+func (n *NotesGui) closeCalendar(g *gocui.Gui, v *gocui.View) error {
+    n.calendarShown = false
+    n.dueFilter = time.Time{}
+    n.updateShownNotes()
+
+    err := n.layout(g)
+    if err != nil {
+        return err
+    }
+    return n.update(g)
+}
+
+// Hides the calendar keeping the notes filtered by the selected day
+func (n *NotesGui) selectCalendarDay(g *gocui.Gui, v *gocui.View) error {
+    n.calendarShown = false
+
+    err := n.layout(g)
+    if err != nil {
+        return err
+    }
+    return n.update(g)
+}
+
+func (n *NotesGui) calendarMove(months int, days int) (func(*gocui.Gui, *gocui.View) error) {
+    return func(g *gocui.Gui, v *gocui.View) error {
+        n.dueFilter = n.dueFilter.AddDate(0, months, days)
+        n.idx = 0
+        n.updateShownNotes()
+        return n.update(g)
+    }
+}
+
+func (n *NotesGui) updateCalendarView(g *gocui.Gui) error {
+    v, err := g.View(CALENDAR_VIEW)
+    if err != nil {
+        return err
+    }
+
+    v.Clear()
+    v.Title = n.dueFilter.Month().String() + " " + strconv.Itoa(n.dueFilter.Year())
+    n.Notes.WriteMonth(v, n.dueFilter, n.dueFilter, false, true)
+    return nil
+}
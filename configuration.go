@@ -19,6 +19,16 @@ type Configuration struct {
     DefaultTags []string `json:"default_tags"`
     DefaultPriority uint `json:"default_priority"`
     DefaultCategory string `json:"default_category"`
+    SharedFolder string `json:"shared_folder"`
+    UserName string `json:"user_name"`
+    TagAliases map[string]string `json:"tag_aliases"`
+    BulkConfirmThreshold int `json:"bulk_confirm_threshold"`
+    Statuses []string `json:"statuses"`
+    DoneStatuses []string `json:"done_statuses"`
+    ReminderCommand string `json:"reminder_command"`
+    ReminderFifo string `json:"reminder_fifo"`
+    ReminderLog string `json:"reminder_log"`
+    ApiToken string `json:"api_token"`
     config_file string
 }
 
@@ -26,15 +36,27 @@ func NewConfiguration() (Configuration) {
     inst := Configuration{}
 
     inst.TimeFormat = "02.01.2006 15:04"
+    inst.DueFormat = "02.01.2006"
     inst.UsePriority = true
     inst.Color = true
     inst.UseDue = true
     inst.DefaultPriority = 3
     inst.DefaultCategory = ""
+    inst.BulkConfirmThreshold = 5
+    inst.Statuses = defaultStatuses()
+    inst.DoneStatuses = defaultDoneStatuses()
 
     return inst
 }
 
+func defaultStatuses() ([]string) {
+    return []string{"todo", "in progress", "review", "waiting", "done", "cancelled"}
+}
+
+func defaultDoneStatuses() ([]string) {
+    return []string{"done", "cancelled"}
+}
+
 func (c *Configuration) Init() (error) {
     app_folder, err := CreateAppFolder()
     if err != nil {
@@ -50,6 +72,23 @@ func (c *Configuration) Init() (error) {
             return err
         }
     }
+
+    // Older configurations might be missing the formats
+    if len(c.TimeFormat) == 0 {
+        c.TimeFormat = "02.01.2006 15:04"
+    }
+    if len(c.DueFormat) == 0 {
+        c.DueFormat = "02.01.2006"
+    }
+    if c.BulkConfirmThreshold <= 0 {
+        c.BulkConfirmThreshold = 5
+    }
+    if len(c.Statuses) == 0 {
+        c.Statuses = defaultStatuses()
+    }
+    if len(c.DoneStatuses) == 0 {
+        c.DoneStatuses = defaultDoneStatuses()
+    }
     return nil
 }
 
@@ -139,30 +178,157 @@ func (c *Configuration) Configure() {
     }
 
     for {
-        catStr, err := Question("Default category (either empty, \"prio\" or \"due\"): ")
+        catStr, err := Question("Default category (either empty, \"prio\", \"due\" or \"status\"): ")
         if err == nil {
-           if len(catStr) == 0 || catStr == "prio" || catStr == "due" {
+           if len(catStr) == 0 || catStr == "prio" || catStr == "due" || catStr == "status" {
                 c.DefaultCategory = catStr
                 break
            }
        }
     }
 
+    for {
+        statusStr, err := Question("Statuses in workflow order (comma separated, empty for current): ")
+        if err == nil {
+            if len(statusStr) == 0 {
+                break
+            }
+
+            statuses := parseStatusList(statusStr)
+            if len(statuses) > 1 {
+                c.Statuses = statuses
+                break
+            }
+        }
+    }
+
+    for {
+        statusStr, err := Question("Statuses meaning the note is done (comma separated, empty for current): ")
+        if err == nil {
+            if len(statusStr) == 0 && c.hasStatuses(c.DoneStatuses) {
+                break
+            }
+
+            statuses := parseStatusList(statusStr)
+            if len(statuses) > 0 && len(statuses) < len(c.Statuses) && c.hasStatuses(statuses) {
+                c.DoneStatuses = statuses
+                break
+            }
+        }
+    }
+
+    for {
+        folder, err := Question("Drive folder id for shared notebook (empty for private notes): ")
+        if err == nil {
+            c.SharedFolder = strings.Trim(folder, " ")
+            break
+        }
+    }
+
+    for {
+        name, err := Question("Your name for assigning notes (empty to use Google account email): ")
+        if err == nil {
+            c.UserName = strings.Trim(name, " ")
+            break
+        }
+    }
+
     c.Save()
 }
 
+// Returns true if notes are kept in a regular Drive folder instead of the
+// application data folder
+func (c *Configuration) IsShared() (bool) {
+    return len(c.SharedFolder) > 0
+}
+
+// Returns the tag the alias refers to. Aliases apply also to the tags under
+// them in the hierarchy.
+func (c *Configuration) CanonicalTag(tag string) (string) {
+    for _, t := range GetTagWithParents(tag) {
+        canonical, ok := c.TagAliases[t]
+        if ok {
+            return canonical + tag[len(t):]
+        }
+    }
+    return tag
+}
+
+// Returns the configured status matching given string. Case, dashes and
+// underscores are ignored so that "In-progress" matches "in progress".
+func (c *Configuration) FindStatus(str string) (string, bool) {
+    str = normalizeStatus(str)
+    for _, status := range c.Statuses {
+        if normalizeStatus(status) == str {
+            return status, true
+        }
+    }
+    return "", false
+}
+
+func (c *Configuration) IsDoneStatus(status string) (bool) {
+    return containsString(c.DoneStatuses, status)
+}
+
+// Returns the status new notes and notes marked not done get
+func (c *Configuration) GetOpenStatus() (string) {
+    for _, status := range c.Statuses {
+        if !c.IsDoneStatus(status) {
+            return status
+        }
+    }
+    return c.Statuses[0]
+}
+
+// Returns the status notes marked done get
+func (c *Configuration) GetDoneStatus() (string) {
+    return c.DoneStatuses[0]
+}
+
+func (c *Configuration) hasStatuses(statuses []string) (bool) {
+    for _, status := range statuses {
+        if !containsString(c.Statuses, status) {
+            return false
+        }
+    }
+    return true
+}
+
+func normalizeStatus(str string) (string) {
+    str = strings.ToLower(strings.Trim(str, " "))
+    str = strings.Replace(str, "-", " ", -1)
+    return strings.Replace(str, "_", " ", -1)
+}
+
+func parseStatusList(str string) ([]string) {
+    var ret []string
+    for _, status := range strings.Split(str, ",") {
+        status = normalizeStatus(status)
+        if len(status) > 0 && !containsString(ret, status) {
+            ret = append(ret, status)
+        }
+    }
+    return ret
+}
+
 func (c *Configuration) Save() (error) {
     jsonStr, err := json.Marshal(c)
     if err != nil {
         return err
     }
 
-    f, err := os.Create(c.config_file)
+    // Configuration contains the API token so only the user can read it.
+    // Files created by older versions are made private as well.
+    f, err := os.OpenFile(c.config_file, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
     if err != nil {
         return err
     }
 
     defer f.Close()
+    err = f.Chmod(0600)
+    if err != nil {
+        return err
+    }
     _, err = f.Write(jsonStr)
 
     if err != nil {
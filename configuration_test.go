@@ -0,0 +1,39 @@
+package main
+
+import (
+    "io/ioutil"
+    "os"
+    "path/filepath"
+    "testing"
+)
+
+func TestConfigurationSaveIsPrivate(t *testing.T) {
+    dir, err := ioutil.TempDir("", "config")
+    if err != nil {
+        t.Fatal(err)
+    }
+    defer os.RemoveAll(dir)
+
+    c := NewConfiguration()
+    c.config_file = filepath.Join(dir, "config.json")
+    c.ApiToken = "secret"
+
+    // Files written by older versions were readable by everyone
+    err = ioutil.WriteFile(c.config_file, []byte("{}"), 0644)
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    err = c.Save()
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    info, err := os.Stat(c.config_file)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if info.Mode().Perm() != 0600 {
+        t.Errorf("Configuration file mode is %v, want 0600", info.Mode().Perm())
+    }
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+    "errors"
+)
+
+func (n *Note) DependsOnNote(id uint) (bool) {
+    for _, dep := range n.DependsOn {
+        if dep == id {
+            return true
+        }
+    }
+    return false
+}
+
+// Makes the note depend on another note. Dependencies that would create
+// a cycle are rejected.
+func (n *Notes) AddDependency(note *Note, dep *Note) (bool, error) {
+    if note.Id == dep.Id {
+        return false, errors.New("Note can't depend on itself")
+    }
+
+    if note.DependsOnNote(dep.Id) {
+        return false, nil
+    }
+
+    if n.dependsOnTransitively(dep, note.Id, map[uint]bool{}) {
+        return false, errors.New("Adding the dependency would create a cycle")
+    }
+
+    note.DependsOn = append(note.DependsOn, dep.Id)
+    return true, nil
+}
+
+func (n *Notes) RemoveDependency(note *Note, id uint) (bool) {
+    for i := 0; i < len(note.DependsOn); i++ {
+        if note.DependsOn[i] == id {
+            note.DependsOn = append(note.DependsOn[:i], note.DependsOn[i+1:]...)
+            return true
+        }
+    }
+    return false
+}
+
+func (n *Notes) dependsOnTransitively(note *Note, id uint, visited map[uint]bool) (bool) {
+    if visited[note.Id] {
+        return false
+    }
+    visited[note.Id] = true
+
+    for _, depId := range note.DependsOn {
+        if depId == id {
+            return true
+        }
+
+        dep := n.FindNote(depId)
+        if dep != nil && n.dependsOnTransitively(dep, id, visited) {
+            return true
+        }
+    }
+    return false
+}
+
+// Returns notes that are not yet done and the note depends on
+func (n *Notes) GetBlockers(note *Note) ([]*Note) {
+    var ret []*Note
+    for _, id := range note.DependsOn {
+        dep := n.FindNote(id)
+        if dep != nil && !dep.Done {
+            ret = append(ret, dep)
+        }
+    }
+    return ret
+}
+
+func (n *Notes) IsBlocked(note *Note) (bool) {
+    return len(n.GetBlockers(note)) > 0
+}
+
+// Returns notes that depend on the note
+func (n *Notes) GetDependents(note *Note) ([]*Note) {
+    var ret []*Note
+    for i, _ := range n.notes {
+        other := &n.notes[i]
+        if other.DependsOnNote(note.Id) {
+            ret = append(ret, other)
+        }
+    }
+    return ret
+}
+
+// Returns notes that depend on the note and are no longer blocked by
+// anything. Used to tell which notes became actionable when note is done.
+func (n *Notes) GetUnblockedDependents(note *Note) ([]*Note) {
+    var ret []*Note
+    for _, dependent := range n.GetDependents(note) {
+        if !dependent.Done && !n.IsBlocked(dependent) {
+            ret = append(ret, dependent)
+        }
+    }
+    return ret
+}
+
+func (n *Notes) FilterBlockedNotes(notes []*Note) []*Note {
+    var ret []*Note
+    for _, note := range notes {
+        if n.IsBlocked(note) {
+            continue
+        }
+        ret = append(ret, note)
+    }
+    return ret
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+    "fmt"
+    "io"
+    "sort"
+    "strings"
+    "time"
+)
+
+// Returns done notes completed between given times ordered by completion.
+// Notes without known completion time are skipped.
+func (n *Notes) GetCompletedNotes(notes []*Note, since time.Time, until time.Time) ([]*Note) {
+    var ret []*Note
+    for _, note := range notes {
+        if !note.Done {
+            continue
+        }
+
+        completedAt := n.GetCompletedTime(note)
+        if completedAt.IsZero() || completedAt.Before(since) || (!until.IsZero() && !completedAt.Before(until)) {
+            continue
+        }
+        ret = append(ret, note)
+    }
+
+    sort.SliceStable(ret, func(i, j int) bool {
+        return n.GetCompletedTime(ret[i]).Before(n.GetCompletedTime(ret[j]))
+    })
+    return ret
+}
+
+// Writes notes completed between given times as Markdown grouped by the day
+// of completion and the first tag of the note
+func (n *Notes) WriteDoneLog(w io.Writer, notes []*Note, since time.Time, until time.Time) {
+    completed := n.GetCompletedNotes(notes, since, until)
+    if len(completed) == 0 {
+        fmt.Fprintln(w, "Nothing done since " + since.Format("Monday 2006-01-02"))
+        return
+    }
+
+    var days []string
+    byDay := map[string]map[string][]*Note{}
+    for _, note := range completed {
+        day := n.GetCompletedTime(note).Local().Format("Monday 2006-01-02")
+        tags, ok := byDay[day]
+        if !ok {
+            tags = map[string][]*Note{}
+            byDay[day] = tags
+            days = append(days, day)
+        }
+
+        tag := "Other"
+        if len(note.Tags) > 0 {
+            tag = n.config.CanonicalTag(note.Tags[0])
+        }
+        tags[tag] = append(tags[tag], note)
+    }
+
+    for i, day := range days {
+        if i > 0 {
+            fmt.Fprintln(w, "")
+        }
+        fmt.Fprintln(w, "## " + day)
+
+        tags := make([]string, 0, len(byDay[day]))
+        for tag := range byDay[day] {
+            tags = append(tags, tag)
+        }
+        sort.Slice(tags, func(i, j int) bool {
+            // Notes without tags are listed last
+            if tags[i] == "Other" {
+                return false
+            }
+            if tags[j] == "Other" {
+                return true
+            }
+            return tags[i] < tags[j]
+        })
+
+        for _, tag := range tags {
+            fmt.Fprintln(w, "")
+            fmt.Fprintln(w, "**" + tag + "**")
+            for _, note := range byDay[day][tag] {
+                line := fmt.Sprintf("- %v (#%v)", strings.Trim(note.GetTitle(), " "), note.Id)
+                if len(note.Tags) > 1 {
+                    line += " [" + strings.Join(note.Tags[1:], ", ") + "]"
+                }
+                fmt.Fprintln(w, line)
+            }
+        }
+    }
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+    "errors"
+    "sort"
+    "strconv"
+    "strings"
+    "time"
+)
+
+const ESTIMATE_PREFIX = "est:"
+
+// Estimated effort of a note either as time or as points
+type Estimate struct {
+    Time time.Duration `json:"time"`
+    Points float64 `json:"points"`
+}
+
+// Estimate compared against the time logged for the notes
+type EstimateAccuracy struct {
+    Notes int
+    Estimated time.Duration
+    Actual time.Duration
+}
+
+// Parses estimate such as "2h", "1h30m", "3d" or "3pts"
+func ParseEstimate(str string) (Estimate, error) {
+    ret := Estimate{}
+    lower := strings.ToLower(strings.Trim(str, " "))
+
+    for _, suffix := range []string{"pts", "pt", "p"} {
+        if strings.HasSuffix(lower, suffix) {
+            points, err := strconv.ParseFloat(strings.TrimSuffix(lower, suffix), 64)
+            if err != nil || points < 0 {
+                return ret, errors.New("Invalid estimate given. Use for example 2h, 1h30m or 3pts")
+            }
+            ret.Points = points
+            return ret, nil
+        }
+    }
+
+    d, err := ParseDuration(lower)
+    if err != nil || d < 0 {
+        return ret, errors.New("Invalid estimate given. Use for example 2h, 1h30m or 3pts")
+    }
+    ret.Time = d
+    return ret, nil
+}
+
+func (e Estimate) IsZero() (bool) {
+    return e.Time == 0 && e.Points == 0
+}
+
+func (e Estimate) String() (string) {
+    if e.Points > 0 {
+        return strconv.FormatFloat(e.Points, 'f', -1, 64) + "pts"
+    }
+    if e.Time > 0 {
+        return FormatDuration(e.Time)
+    }
+    return ""
+}
+
+// Removes "est:" token from the content and returns the estimate given in it
+func ExtractEstimate(content string) (string, Estimate, error) {
+    var words []string
+    estimate := Estimate{}
+    for _, word := range strings.Split(content, " ") {
+        if strings.HasPrefix(strings.ToLower(word), ESTIMATE_PREFIX) {
+            e, err := ParseEstimate(word[len(ESTIMATE_PREFIX):])
+            if err != nil {
+                return content, estimate, err
+            }
+            estimate = e
+            continue
+        }
+        words = append(words, word)
+    }
+    return strings.Join(words, " "), estimate, nil
+}
+
+// Returns sum of the estimates of the notes
+func SumEstimates(notes []*Note) (Estimate) {
+    ret := Estimate{}
+    for _, note := range notes {
+        ret.Time += note.Estimate.Time
+        ret.Points += note.Estimate.Points
+    }
+    return ret
+}
+
+// Returns summary such as "12 notes, 31h estimated"
+func GetEstimateSummary(notes []*Note) (string) {
+    ret := strconv.Itoa(len(notes)) + " notes"
+    sum := SumEstimates(notes)
+    if sum.Time > 0 {
+        ret += ", " + FormatHours(sum.Time) + " estimated"
+    }
+    if sum.Points > 0 {
+        ret += ", " + strconv.FormatFloat(sum.Points, 'f', -1, 64) + "pts estimated"
+    }
+    return ret
+}
+
+// Returns duration in hours such as "31h" or "2.5h"
+func FormatHours(d time.Duration) (string) {
+    return strings.TrimSuffix(strconv.FormatFloat(d.Hours(), 'f', 1, 64), ".0") + "h"
+}
+
+// Compares time estimates of the notes against the time logged for them
+// grouped by tag. Notes without time estimate are skipped. Tags are ordered
+// by the ratio of logged and estimated time, the most under-estimated first.
+func (n *Notes) GetEstimateReport(notes []*Note) ([]string, map[string]*EstimateAccuracy) {
+    ret := map[string]*EstimateAccuracy{}
+    for _, note := range notes {
+        if note.Estimate.Time == 0 {
+            continue
+        }
+
+        var keys []string
+        for _, tag := range note.Tags {
+            keys = append(keys, n.config.CanonicalTag(tag))
+        }
+        if len(keys) == 0 {
+            keys = append(keys, "(no tag)")
+        }
+
+        for _, key := range keys {
+            accuracy, ok := ret[key]
+            if !ok {
+                accuracy = &EstimateAccuracy{}
+                ret[key] = accuracy
+            }
+            accuracy.Notes++
+            accuracy.Estimated += note.Estimate.Time
+            accuracy.Actual += note.GetTrackedTime()
+        }
+    }
+
+    keys := make([]string, 0, len(ret))
+    for key := range ret {
+        keys = append(keys, key)
+    }
+    sort.Slice(keys, func(i, j int) bool {
+        a := ret[keys[i]].GetRatio()
+        b := ret[keys[j]].GetRatio()
+        if a != b {
+            return a > b
+        }
+        return keys[i] < keys[j]
+    })
+    return keys, ret
+}
+
+// Returns the logged time relative to the estimate. Values over one mean
+// the notes were under-estimated.
+func (a *EstimateAccuracy) GetRatio() (float64) {
+    if a.Estimated == 0 {
+        return 0
+    }
+    return float64(a.Actual) / float64(a.Estimated)
+}
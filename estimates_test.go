@@ -0,0 +1,70 @@
+package main
+
+import (
+    "reflect"
+    "testing"
+    "time"
+)
+
+func TestParseEstimate(t *testing.T) {
+    tests := []struct {
+        str string
+        estimate Estimate
+        err bool
+    }{
+        {"2h", Estimate{Time: 2 * time.Hour}, false},
+        {"1h30m", Estimate{Time: 90 * time.Minute}, false},
+        {"3d", Estimate{Time: 3 * 24 * time.Hour}, false},
+        {"3pts", Estimate{Points: 3}, false},
+        {"1.5pt", Estimate{Points: 1.5}, false},
+        {" 5P ", Estimate{Points: 5}, false},
+        {"-2h", Estimate{}, true},
+        {"-1pts", Estimate{}, true},
+        {"xpts", Estimate{}, true},
+        {"soon", Estimate{}, true},
+    }
+
+    for _, test := range tests {
+        estimate, err := ParseEstimate(test.str)
+        if test.err {
+            if err == nil {
+                t.Errorf("ParseEstimate(%q) should fail", test.str)
+            }
+            continue
+        }
+        if err != nil {
+            t.Errorf("ParseEstimate(%q) failed: %v", test.str, err)
+            continue
+        }
+        if estimate != test.estimate {
+            t.Errorf("ParseEstimate(%q) = %+v, want %+v", test.str, estimate, test.estimate)
+        }
+    }
+}
+
+func TestGetEstimateReport(t *testing.T) {
+    start := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+    tracked := func(d time.Duration) ([]TimeEntry) {
+        return []TimeEntry{{Start: start, End: start.Add(d)}}
+    }
+
+    n := newTestNotes(
+        Note{Id: 1, Tags: []string{"backend"}, Done: true, Estimate: Estimate{Time: 2 * time.Hour}, TimeEntries: tracked(4 * time.Hour)},
+        Note{Id: 2, Tags: []string{"docs"}, Done: true, Estimate: Estimate{Time: 2 * time.Hour}, TimeEntries: tracked(time.Hour)},
+        Note{Id: 3, Tags: []string{"frontend"}, Done: true, Estimate: Estimate{Time: time.Hour}, TimeEntries: tracked(3 * time.Hour)},
+        Note{Id: 4, Done: true, Estimate: Estimate{Time: time.Hour}, TimeEntries: tracked(time.Hour)},
+        Note{Id: 5, Tags: []string{"docs"}, Estimate: Estimate{Time: time.Hour}, TimeEntries: tracked(5 * time.Hour)},
+        Note{Id: 6, Tags: []string{"docs"}, Done: true, Estimate: Estimate{Points: 3}},
+    )
+
+    keys, report := n.GetEstimateReport(n.OnlyDoneNotes(n.GetNotes()))
+    expected := []string{"frontend", "backend", "(no tag)", "docs"}
+    if !reflect.DeepEqual(keys, expected) {
+        t.Errorf("GetEstimateReport() keys = %v, want %v", keys, expected)
+    }
+
+    docs := report["docs"]
+    if docs.Notes != 1 || docs.Estimated != 2 * time.Hour || docs.Actual != time.Hour || docs.GetRatio() != 0.5 {
+        t.Errorf("GetEstimateReport() docs = %+v", docs)
+    }
+}
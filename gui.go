@@ -2,6 +2,8 @@ package main
 
 import (
     "fmt"
+    "log"
+    "os"
     "strings"
     "strconv"
     "time"
@@ -14,6 +16,7 @@ import (
 const (
     LIST_VIEW = "list"
     PREVIEW_VIEW = "preview"
+    ACTIVITY_VIEW = "activity"
     COMMAND_VIEW = "cmd"
     HELP_VIEW = "help"
 )
@@ -31,11 +34,47 @@ type NotesGui struct {
     statusString string
     showNoteContent bool
     showDone bool
+    showMine bool
+    showDeferred bool
     SaveModifications bool
     unsavedModifications bool
     searchStr string
+    linkHistory []uint
+    treeMode bool
+    collapsed map[uint]bool
+    depths map[uint]int
+    marks map[uint]bool
+    visualMode bool
+    visualStart int
     sortColumns []string
     category string
+    boardMode bool
+    boardCategory string
+    calendarShown bool
+    dueFilter time.Time
+    commandLine string
+    templates []*Template
+    templatePickerShown bool
+    templateIdx int
+    pendingTemplate *Template
+    templateValues map[string]string
+    gui *gocui.Gui
+    savesInProgress int
+}
+
+// Shows log messages in the status bar as writing them to the terminal would
+// garble the views
+type statusLogWriter struct {
+    n *NotesGui
+}
+
+func (w *statusLogWriter) Write(p []byte) (int, error) {
+    msg := strings.TrimSpace(string(p))
+    w.n.gui.Update(func(g *gocui.Gui) error {
+        w.n.statusString = msg
+        return w.n.update(g)
+    })
+    return len(p), nil
 }
 
 func (n *NotesGui) Start() (error) {
@@ -45,10 +84,13 @@ func (n *NotesGui) Start() (error) {
     }
 
     n.tagIdx = -1
+    n.collapsed = map[uint]bool{}
+    n.marks = map[uint]bool{}
     n.updateShownNotes()
     n.category = n.Config.DefaultCategory
 
     defer g.Close()
+    n.gui = g
     g.SetManagerFunc(n.layout)
     g.InputEsc = true
     g.Cursor = false
@@ -75,6 +117,16 @@ func (n *NotesGui) Start() (error) {
         return err
     }
 
+    err = g.SetKeybinding(LIST_VIEW, 'H', gocui.ModNone, n.parentTag)
+    if err != nil {
+        return err
+    }
+
+    err = g.SetKeybinding(LIST_VIEW, 'L', gocui.ModNone, n.childTag)
+    if err != nil {
+        return err
+    }
+
     err = g.SetKeybinding(LIST_VIEW, 'G', gocui.ModNone, n.gotoBottom)
     if err != nil {
         return err
@@ -100,6 +152,86 @@ func (n *NotesGui) Start() (error) {
         return err
     }
 
+    err = g.SetKeybinding(LIST_VIEW, 'c', gocui.ModNone, n.startComment)
+    if err != nil {
+        return err
+    }
+
+    err = g.SetKeybinding(LIST_VIEW, 'f', gocui.ModNone, n.followLink)
+    if err != nil {
+        return err
+    }
+
+    err = g.SetKeybinding(LIST_VIEW, 'b', gocui.ModNone, n.followBack)
+    if err != nil {
+        return err
+    }
+
+    err = g.SetKeybinding(LIST_VIEW, 'm', gocui.ModNone, n.toggleMark)
+    if err != nil {
+        return err
+    }
+
+    err = g.SetKeybinding(LIST_VIEW, 'V', gocui.ModNone, n.toggleVisualMode)
+    if err != nil {
+        return err
+    }
+
+    err = g.SetKeybinding(LIST_VIEW, 'T', gocui.ModNone, n.toggleTree)
+    if err != nil {
+        return err
+    }
+
+    err = g.SetKeybinding(LIST_VIEW, 'z', gocui.ModNone, n.toggleCollapsed)
+    if err != nil {
+        return err
+    }
+
+    err = g.SetKeybinding(LIST_VIEW, 't', gocui.ModNone, n.toggleTimer)
+    if err != nil {
+        return err
+    }
+
+    err = g.SetKeybinding(LIST_VIEW, 's', gocui.ModNone, n.cycleStatus)
+    if err != nil {
+        return err
+    }
+
+    err = g.SetKeybinding(LIST_VIEW, 'C', gocui.ModNone, n.openCalendar)
+    if err != nil {
+        return err
+    }
+
+    err = n.setCalendarKeybindings(g)
+    if err != nil {
+        return err
+    }
+
+    err = n.setTemplateKeybindings(g)
+    if err != nil {
+        return err
+    }
+
+    err = g.SetKeybinding(LIST_VIEW, 'B', gocui.ModNone, n.toggleBoard)
+    if err != nil {
+        return err
+    }
+
+    err = n.setBoardKeybindings(g)
+    if err != nil {
+        return err
+    }
+
+    err = g.SetKeybinding(LIST_VIEW, 'A', gocui.ModNone, n.toggleShowMine)
+    if err != nil {
+        return err
+    }
+
+    err = g.SetKeybinding(LIST_VIEW, 'J', gocui.ModNone, n.openJournal)
+    if err != nil {
+        return err
+    }
+
     err = g.SetKeybinding(LIST_VIEW, gocui.KeySpace, gocui.ModNone, n.toggleDone)
     if err != nil {
         return err
@@ -210,7 +342,24 @@ func (n *NotesGui) Start() (error) {
         return err
     }
 
+    err = g.SetKeybinding("", gocui.KeyF10, gocui.ModNone, n.toggleShowDeferred)
+    if err != nil {
+        return err
+    }
+
+    log.SetOutput(&statusLogWriter{n})
+    log.SetFlags(0)
+    defer log.SetFlags(log.LstdFlags)
+    defer log.SetOutput(os.Stderr)
+
+    // Current user is looked up before starting as it may block
+    n.Notes.GetCurrentUser()
+
+    done := make(chan struct{})
+    defer close(done)
+
     g.Update(n.update)
+    go n.refreshTimer(g, done)
     err = g.MainLoop()
     if err != nil && err != gocui.ErrQuit {
         return err
@@ -219,6 +368,27 @@ func (n *NotesGui) Start() (error) {
     return nil
 }
 
+// Refreshes the running timer in the command bar every second until done is
+// closed
+func (n *NotesGui) refreshTimer(g *gocui.Gui, done <-chan struct{}) {
+    ticker := time.NewTicker(time.Second)
+    defer ticker.Stop()
+    for {
+        select {
+            case <-done:
+                return
+            case <-ticker.C:
+                g.Update(func(g *gocui.Gui) error {
+                    _, entry := n.Notes.GetRunningTimer()
+                    if entry == nil {
+                        return nil
+                    }
+                    return n.renderCommandView(g)
+                })
+        }
+    }
+}
+
 func (n *NotesGui) layout(g *gocui.Gui) (error) {
     maxX, maxY := g.Size()
     _, err := g.SetView(COMMAND_VIEW, 0, maxY-2, maxX, maxY)
@@ -238,7 +408,8 @@ func (n *NotesGui) layout(g *gocui.Gui) (error) {
         return err
     }
 
-    _, err = g.SetView(PREVIEW_VIEW, maxX/2, 0, maxX-2, maxY-2)
+    activityY := maxY * 2 / 3
+    _, err = g.SetView(PREVIEW_VIEW, maxX/2, 0, maxX-2, activityY-1)
     if err != nil && err != gocui.ErrUnknownView {
         return err
     }
@@ -250,6 +421,72 @@ func (n *NotesGui) layout(g *gocui.Gui) (error) {
     v.Wrap = true
     v.Autoscroll = true
 
+    _, err = g.SetView(ACTIVITY_VIEW, maxX/2, activityY, maxX-2, maxY-2)
+    if err != nil && err != gocui.ErrUnknownView {
+        return err
+    }
+
+    v, err = g.View(ACTIVITY_VIEW)
+    if err != nil {
+        return err
+    }
+    v.Title = "Activity"
+    v.Wrap = true
+    v.Autoscroll = true
+
+    if n.boardMode {
+        _, err = g.SetView(BOARD_VIEW, 0, 0, maxX-1, maxY-2)
+        if err != nil && err != gocui.ErrUnknownView {
+            return err
+        }
+        _, err = g.SetViewOnTop(BOARD_VIEW)
+        if err != nil {
+            return err
+        }
+    } else {
+        err = g.DeleteView(BOARD_VIEW)
+        if err != nil && err != gocui.ErrUnknownView {
+            return err
+        }
+    }
+
+    if n.templatePickerShown {
+        height := len(n.templates) + 3
+        _, err = g.SetView(TEMPLATE_VIEW, maxX/2-20, maxY/2-height/2, maxX/2+20, maxY/2+height/2+1)
+        if err != nil && err != gocui.ErrUnknownView {
+            return err
+        }
+        _, err = g.SetViewOnTop(TEMPLATE_VIEW)
+        if err != nil {
+            return err
+        }
+    } else {
+        err = g.DeleteView(TEMPLATE_VIEW)
+        if err != nil && err != gocui.ErrUnknownView {
+            return err
+        }
+    }
+
+    if n.calendarShown {
+        calendarY := maxY - 12
+        if calendarY < 0 {
+            calendarY = 0
+        }
+        _, err = g.SetView(CALENDAR_VIEW, maxX/2, calendarY, maxX-2, maxY-2)
+        if err != nil && err != gocui.ErrUnknownView {
+            return err
+        }
+        _, err = g.SetViewOnTop(CALENDAR_VIEW)
+        if err != nil {
+            return err
+        }
+    } else {
+        err = g.DeleteView(CALENDAR_VIEW)
+        if err != nil && err != gocui.ErrUnknownView {
+            return err
+        }
+    }
+
     return nil
 }
 
@@ -270,10 +507,27 @@ func (n *NotesGui) updateShownNotes() {
         n.shownNotes = n.Notes.FilterDoneNotes(n.shownNotes)
     }
 
+    if !n.showDeferred {
+        n.shownNotes = n.Notes.FilterDeferredNotes(n.shownNotes)
+    }
+
     if len(n.tagFilter) > 0 {
         n.shownNotes = n.Notes.FilterNotesByTag(n.tagFilter, n.shownNotes)
     }
 
+    if n.showMine {
+        n.shownNotes = n.Notes.FilterNotesByAssignee("me", n.shownNotes)
+    }
+
+    if !n.dueFilter.IsZero() {
+        n.shownNotes = n.Notes.FilterNotesByDueDay(n.dueFilter, n.shownNotes)
+    }
+
+    if n.isTreeShown() {
+        n.shownNotes, n.depths = n.Notes.OrderAsTree(n.shownNotes)
+        n.shownNotes = n.hideCollapsed(n.shownNotes)
+    }
+
     if len(n.shownNotes) == 0 {
         n.selectedNote = nil
         return
@@ -295,6 +549,149 @@ func (n *NotesGui) updateShownNotes() {
     }
 }
 
+func (n *NotesGui) isTreeShown() (bool) {
+    return n.treeMode && len(n.category) == 0 && !n.boardMode
+}
+
+// Removes sub notes of collapsed notes. Notes must be in tree order.
+func (n *NotesGui) hideCollapsed(notes []*Note) ([]*Note) {
+    var ret []*Note
+    skipDepth := -1
+    for _, note := range notes {
+        depth := n.depths[note.Id]
+        if skipDepth >= 0 && depth > skipDepth {
+            continue
+        }
+
+        skipDepth = -1
+        ret = append(ret, note)
+        if n.collapsed[note.Id] {
+            skipDepth = depth
+        }
+    }
+    return ret
+}
+
+func (n *NotesGui) toggleTree(g *gocui.Gui, v *gocui.View) error {
+    n.treeMode = !n.treeMode
+    n.updateShownNotes()
+    return n.update(g)
+}
+
+func (n *NotesGui) toggleCollapsed(g *gocui.Gui, v *gocui.View) error {
+    if n.selectedNote == nil || !n.isTreeShown() {
+        return nil
+    }
+
+    if len(n.Notes.GetChildren(n.selectedNote)) == 0 {
+        return nil
+    }
+
+    n.collapsed[n.selectedNote.Id] = !n.collapsed[n.selectedNote.Id]
+    n.updateShownNotes()
+    return n.update(g)
+}
+
+// Selects given note clearing the filters if the note is not shown
+// Selects the note clearing the filters hiding it. Returns false and keeps
+// the current selection if the note is still hidden.
+func (n *NotesGui) selectNote(note *Note) (bool) {
+    found := false
+    for _, shown := range n.shownNotes {
+        if shown.Id == note.Id {
+            found = true
+            break
+        }
+    }
+
+    if !found {
+        n.tagIdx = -1
+        n.tagFilter = ""
+        n.searchStr = ""
+        if strings.HasPrefix(n.cmd, "/") {
+            n.cmd = ""
+        }
+        n.showMine = false
+        n.dueFilter = time.Time{}
+        if note.Done {
+            n.showDone = true
+        }
+        if note.IsDeferred() {
+            n.showDeferred = true
+        }
+
+        // Expand all ancestors so that the note is visible
+        visited := map[uint]bool{note.Id: true}
+        parent := n.Notes.FindNote(note.Parent)
+        for parent != nil && !visited[parent.Id] {
+            visited[parent.Id] = true
+            delete(n.collapsed, parent.Id)
+            parent = n.Notes.FindNote(parent.Parent)
+        }
+    }
+
+    previous := n.selectedNote
+    n.selectedNote = note
+    n.shownNotes = nil
+    n.updateShownNotes()
+    if n.selectedNote == note {
+        return true
+    }
+
+    n.selectedNote = previous
+    n.shownNotes = nil
+    n.updateShownNotes()
+    return false
+}
+
+func (n *NotesGui) setHiddenStatus(note *Note) {
+    n.statusString = "Note #" + strconv.Itoa(int(note.Id)) + " is hidden by the current filter"
+}
+
+func (n *NotesGui) followLinkNum(g *gocui.Gui, num int) error {
+    if n.selectedNote == nil {
+        return nil
+    }
+
+    links := n.Notes.GetLinkedNotes(n.selectedNote)
+    if len(links) == 0 {
+        n.statusString = "Selected note does not link to other notes"
+        return n.update(g)
+    }
+
+    if num < 1 || num > len(links) {
+        n.statusString = "Invalid link number. Note has " + strconv.Itoa(len(links)) + " links"
+        return n.update(g)
+    }
+
+    current := n.selectedNote.Id
+    if !n.selectNote(links[num-1]) {
+        n.setHiddenStatus(links[num-1])
+        return n.update(g)
+    }
+    n.linkHistory = append(n.linkHistory, current)
+    return n.update(g)
+}
+
+func (n *NotesGui) followLink(g *gocui.Gui, v *gocui.View) error {
+    return n.followLinkNum(g, 1)
+}
+
+func (n *NotesGui) followBack(g *gocui.Gui, v *gocui.View) error {
+    for len(n.linkHistory) > 0 {
+        id := n.linkHistory[len(n.linkHistory)-1]
+        n.linkHistory = n.linkHistory[:len(n.linkHistory)-1]
+        note := n.Notes.FindNote(id)
+        if note != nil {
+            if !n.selectNote(note) {
+                n.setHiddenStatus(note)
+            }
+            break
+        }
+    }
+    return n.update(g)
+}
+
 func (n *NotesGui) toggleContent(g *gocui.Gui, v *gocui.View) error {
     n.showNoteContent = !n.showNoteContent
     return n.update(g)
@@ -347,6 +744,38 @@ func (n *NotesGui) decreaseTagIndex(g *gocui.Gui, v *gocui.View) error {
     return n.update(g)
 }
 
+// Sets the tag filter and syncs the index used when cycling the tags
+func (n *NotesGui) setTagFilter(tag string) {
+    n.tagFilter = tag
+    n.tagIdx = -1
+    for i, t := range n.Notes.GetTagKeys() {
+        if t == tag {
+            n.tagIdx = i
+            break
+        }
+    }
+    n.updateShownNotes()
+}
+
+// Moves tag filter up in the tag hierarchy
+func (n *NotesGui) parentTag(g *gocui.Gui, v *gocui.View) error {
+    if len(n.tagFilter) == 0 {
+        return nil
+    }
+    n.setTagFilter(GetParentTag(n.tagFilter))
+    return n.update(g)
+}
+
+// Moves tag filter down in the tag hierarchy
+func (n *NotesGui) childTag(g *gocui.Gui, v *gocui.View) error {
+    children := n.Notes.GetChildTags(n.tagFilter)
+    if len(children) == 0 {
+        return nil
+    }
+    n.setTagFilter(children[0])
+    return n.update(g)
+}
+
 func (n *NotesGui) gotoBottom(g *gocui.Gui, v *gocui.View) error {
     n.updateShownNotes()
     if len(n.shownNotes) > 0 {
@@ -382,44 +811,252 @@ func (n *NotesGui) editNote(g *gocui.Gui, v *gocui.View) error {
         n.unsavedModifications = true
         n.handleAsyncSave()
     }
-    return n.update(g)
+    return n.update(g)
+}
+
+// Shows template picker or adds empty note if there are no templates
+func (n *NotesGui) addNote(g *gocui.Gui, v *gocui.View) error {
+    templates, err := LoadTemplates()
+    if err != nil {
+        n.statusString = err.Error()
+        return n.update(g)
+    }
+
+    if len(templates) == 0 {
+        return n.addNoteFromTemplate(g, nil, nil)
+    }
+    n.templates = templates
+    return n.openTemplatePicker(g)
+}
+
+// Adds new note from the template with $EDITOR. Empty note is used if the
+// template is nil.
+func (n *NotesGui) addNoteFromTemplate(g *gocui.Gui, template *Template, values map[string]string) error {
+    note := Note{Priority: n.Config.DefaultPriority}
+    if template != nil {
+        t, err := n.Notes.ApplyTemplate(template, values)
+        if err != nil {
+            n.statusString = err.Error()
+            return n.update(g)
+        }
+        note = t
+    }
+
+    modified, err := note.EditInEditor()
+    if err != nil {
+        return err
+    }
+    termbox.Sync()
+    if modified {
+        n.Notes.AddNote(note)
+        n.unsavedModifications = true
+        n.handleAsyncSave()
+    } else if template != nil {
+        n.statusString = "Template was not edited, note not added"
+    }
+    n.updateShownNotes()
+    n.cmd = ""
+    return n.update(g)
+}
+
+// Selects journal note of today creating it if needed
+func (n *NotesGui) openJournal(g *gocui.Gui, v *gocui.View) error {
+    note, created := n.Notes.GetOrCreateJournalNote(time.Now())
+    if created {
+        n.unsavedModifications = true
+        n.handleAsyncSave()
+        n.statusString = "Created journal note " + note.GetTitle()
+    }
+
+    if !n.selectNote(note) {
+        n.setHiddenStatus(note)
+    }
+    return n.update(g)
+}
+
+func (n *NotesGui) deleteNote(g *gocui.Gui, v *gocui.View) error {
+    notes := n.getTargetNotes()
+    if len(notes) == 0 {
+        return nil
+    }
+
+    // Deleting notes invalidates the note pointers so collect the ids first
+    var ids []uint
+    for _, note := range notes {
+        ids = append(ids, note.Id)
+    }
+
+    for _, id := range ids {
+        n.Notes.DeleteNote(id)
+    }
+    n.clearMarks()
+    n.unsavedModifications = true
+    n.handleAsyncSave()
+    if len(ids) > 1 {
+        n.statusString = strconv.Itoa(len(ids)) + " notes deleted"
+    }
+    return n.decreaseIndex(g, v)
+}
+
+func (n *NotesGui) toggleDone(g *gocui.Gui, v *gocui.View) error {
+    notes := n.getTargetNotes()
+    if len(notes) == 0 {
+        return nil
+    }
+
+    // Multiple notes are all marked done unless they all are already done
+    done := false
+    for _, note := range notes {
+        if !note.Done {
+            done = true
+            break
+        }
+    }
+
+    var unblocked []*Note
+    for _, note := range notes {
+        n.Notes.SetDone(note, done)
+    }
+    if done {
+        for _, note := range notes {
+            unblocked = append(unblocked, n.Notes.GetUnblockedDependents(note)...)
+        }
+    }
+
+    if len(unblocked) > 0 {
+        n.statusString = "Now actionable: " + strings.Join(GetNoteReferences(unblocked), ", ")
+    }
+    n.clearMarks()
+    n.unsavedModifications = true
+    n.handleAsyncSave()
+    n.updateShownNotes()
+    return n.update(g)
+}
+
+// Starts timer for the selected note or stops it if it is already running
+func (n *NotesGui) toggleTimer(g *gocui.Gui, v *gocui.View) error {
+    if n.selectedNote == nil {
+        return nil
+    }
+
+    running, entry := n.Notes.GetRunningTimer()
+    if running != nil && running.Id == n.selectedNote.Id {
+        n.Notes.StopTimer()
+        n.statusString = "Stopped timer after " + FormatDuration(entry.GetDuration().Round(time.Minute))
+    } else {
+        err := n.Notes.StartTimer(n.selectedNote)
+        if err != nil {
+            n.statusString = err.Error()
+            return n.update(g)
+        }
+        n.statusString = "Started timer for note " + strconv.Itoa(int(n.selectedNote.Id))
+    }
+
+    n.unsavedModifications = true
+    n.handleAsyncSave()
+    return n.update(g)
+}
+
+// Moves selected or marked notes to the status following the status of the
+// first note
+func (n *NotesGui) cycleStatus(g *gocui.Gui, v *gocui.View) error {
+    notes := n.getTargetNotes()
+    if len(notes) == 0 {
+        return nil
+    }
+
+    status := n.Notes.GetNextStatus(notes[0])
+    for _, note := range notes {
+        err := n.Notes.SetStatus(note, status)
+        if err != nil {
+            n.statusString = err.Error()
+            return n.update(g)
+        }
+    }
+
+    n.statusString = "Status set to " + status
+    n.clearMarks()
+    n.unsavedModifications = true
+    n.handleAsyncSave()
+    n.updateShownNotes()
+    return n.update(g)
+}
+
+// Returns notes the actions apply to. These are the marked notes and the
+// notes selected in visual mode or the selected note if nothing is marked.
+func (n *NotesGui) getTargetNotes() ([]*Note) {
+    var ret []*Note
+    for i, note := range n.shownNotes {
+        if n.isMarked(i, note) {
+            ret = append(ret, note)
+        }
+    }
+
+    if len(ret) == 0 && n.selectedNote != nil {
+        ret = append(ret, n.selectedNote)
+    }
+    return ret
+}
+
+// Returns true if the note at given index of shown notes is marked
+func (n *NotesGui) isMarked(idx int, note *Note) (bool) {
+    if n.marks[note.Id] {
+        return true
+    }
+
+    if !n.visualMode {
+        return false
+    }
+
+    start := n.visualStart
+    end := n.idx
+    if start > end {
+        start, end = end, start
+    }
+    return idx >= start && idx <= end
 }
 
-func (n *NotesGui) addNote(g *gocui.Gui, v *gocui.View) error {
-    note := Note{Priority: n.Config.DefaultPriority}
-    modified, err := note.EditInEditor()
-    if err != nil {
-        return err
-    }
-    termbox.Sync()
-    if modified {
-        n.Notes.AddNote(note)
-        n.unsavedModifications = true
-        n.handleAsyncSave()
+func (n *NotesGui) countMarked() (int) {
+    count := 0
+    for i, note := range n.shownNotes {
+        if n.isMarked(i, note) {
+            count++
+        }
     }
-    n.updateShownNotes()
-    n.cmd = ""
-    return n.update(g)
+    return count
 }
 
-func (n *NotesGui) deleteNote(g *gocui.Gui, v *gocui.View) error {
+func (n *NotesGui) clearMarks() {
+    n.marks = map[uint]bool{}
+    n.visualMode = false
+}
+
+func (n *NotesGui) toggleMark(g *gocui.Gui, v *gocui.View) error {
     if n.selectedNote == nil {
         return nil
     }
-    n.Notes.DeleteNote(n.selectedNote.Id)
-    n.unsavedModifications = true
-    n.handleAsyncSave()
-    return n.decreaseIndex(g, v)
+
+    if n.marks[n.selectedNote.Id] {
+        delete(n.marks, n.selectedNote.Id)
+    } else {
+        n.marks[n.selectedNote.Id] = true
+    }
+    return n.increaseIndex(g, v)
 }
 
-func (n *NotesGui) toggleDone(g *gocui.Gui, v *gocui.View) error {
-    if n.selectedNote == nil {
-        return nil
+// Starts visual mode or ends it keeping the notes in the range marked
+func (n *NotesGui) toggleVisualMode(g *gocui.Gui, v *gocui.View) error {
+    if n.visualMode {
+        for i, note := range n.shownNotes {
+            if n.isMarked(i, note) {
+                n.marks[note.Id] = true
+            }
+        }
+        n.visualMode = false
+    } else if n.selectedNote != nil {
+        n.visualMode = true
+        n.visualStart = n.idx
     }
-    n.selectedNote.Done = !n.selectedNote.Done
-    n.unsavedModifications = true
-    n.handleAsyncSave()
-    n.updateShownNotes()
     return n.update(g)
 }
 
@@ -442,8 +1079,23 @@ func (n *NotesGui) startCommand(g *gocui.Gui, v *gocui.View) error {
     return n.update(g)
 }
 
+func (n *NotesGui) startComment(g *gocui.Gui, v *gocui.View) error {
+    if n.selectedNote == nil {
+        return nil
+    }
+    n.cmd = ":c "
+    _, err := g.SetCurrentView(COMMAND_VIEW)
+    if err != nil {
+        return err
+    }
+    return n.update(g)
+}
+
 func (n *NotesGui) backspaceCommand(g *gocui.Gui, v *gocui.View) error {
     sz := len(n.cmd)
+    if n.pendingTemplate != nil && sz <= len(n.getTemplatePrompt()) {
+        return nil
+    }
     if sz > 0 {
         n.cmd = n.cmd[:sz-1]
     }
@@ -451,23 +1103,36 @@ func (n *NotesGui) backspaceCommand(g *gocui.Gui, v *gocui.View) error {
     return n.update(g)
 }
 
+// Saves the notes in the background. Result of the save is applied in the
+// main loop as the notes may have been merged with changes made by others.
 func (n *NotesGui) handleAsyncSave() {
-    ch := make(chan error)
+    ch := make(chan func() (error))
     n.Notes.AsyncSaveNotes(ch)
+    n.savesInProgress++
 
-    go func(ch <-chan error, n *NotesGui) {
-        err := <-ch
-        if err != nil {
-            n.statusString = err.Error()
-        } else {
-            n.unsavedModifications = false
-        }
+    go func(ch <-chan func() (error), n *NotesGui) {
+        finish := <-ch
+        n.gui.Update(func(g *gocui.Gui) error {
+            n.savesInProgress--
+            err := finish()
+            if err != nil {
+                n.statusString = err.Error()
+            } else if n.savesInProgress == 0 {
+                n.unsavedModifications = false
+            }
+            n.updateShownNotes()
+            return n.update(g)
+        })
     }(ch, n)
 }
 
 func (n *NotesGui) executeCommand(g *gocui.Gui, v *gocui.View) error {
+    if n.pendingTemplate != nil {
+        return n.answerTemplatePrompt(g)
+    }
+
     if strings.HasPrefix(n.cmd, "/") {
-        _, err := g.SetCurrentView(LIST_VIEW)
+        _, err := g.SetCurrentView(n.mainView())
         if err != nil {
             return err
         }
@@ -494,15 +1159,19 @@ func (n *NotesGui) executeCommand(g *gocui.Gui, v *gocui.View) error {
             if n.unsavedModifications {
                 err := n.Notes.SaveNotes()
                 if err != nil {
-                    return err
+                    n.statusString = err.Error()
+                    n.updateShownNotes()
+                    break
                 }
             }
             return gocui.ErrQuit
         case "w":
             if n.unsavedModifications {
                 err := n.Notes.SaveNotes()
+                n.updateShownNotes()
                 if err != nil {
-                    return err
+                    n.statusString = err.Error()
+                    break
                 }
                 n.statusString = "Notes saved"
                 n.unsavedModifications = false
@@ -513,8 +1182,12 @@ func (n *NotesGui) executeCommand(g *gocui.Gui, v *gocui.View) error {
             return n.showHelp(g)
 
         case "a":
-            note := Note{Priority: n.Config.DefaultPriority}
-            note.Content = strings.Join(parts[1:], " ")
+            content, estimate, err := ExtractEstimate(strings.Join(parts[1:], " "))
+            if err != nil {
+                n.statusString = err.Error()
+                break
+            }
+            note := Note{Priority: n.Config.DefaultPriority, Content: content, Estimate: estimate}
             n.Notes.AddNote(note)
             n.unsavedModifications = true
             n.handleAsyncSave()
@@ -522,35 +1195,52 @@ func (n *NotesGui) executeCommand(g *gocui.Gui, v *gocui.View) error {
             break
 
         case "at":
-            if n.selectedNote == nil {
+            notes := n.getTargetNotes()
+            if len(notes) == 0 {
                 n.statusString = "Could not find note"
                 break
             }
             tagStr := strings.Join(parts[1:], " ")
             tags := strings.Split(tagStr, ",")
-            for _, tag := range tags {
-                if n.selectedNote.AddTag(tag) {
-                    n.unsavedModifications = true
-                    n.handleAsyncSave()
-                    n.statusString = "Tags added"
+            modified := false
+            for _, note := range notes {
+                for _, tag := range tags {
+                    if note.AddTag(tag) {
+                        modified = true
+                    }
                 }
             }
+            if modified {
+                n.unsavedModifications = true
+                n.handleAsyncSave()
+                n.statusString = "Tags added"
+            }
+            n.clearMarks()
             break
 
         case "rt":
-            if n.selectedNote == nil {
+            notes := n.getTargetNotes()
+            if len(notes) == 0 {
                 n.statusString = "Could not find note"
                 break
             }
             tagStr := strings.Join(parts[1:], " ")
             tags := strings.Split(tagStr, ",")
-            for _, tag := range tags {
-                if n.selectedNote.RemoveTag(tag) {
-                    n.unsavedModifications = true
-                    n.handleAsyncSave()
-                    n.statusString = "Tags removed"
+            modified := false
+            for _, note := range notes {
+                for _, tag := range tags {
+                    if note.RemoveTag(tag) {
+                        modified = true
+                    }
                 }
             }
+            if modified {
+                n.unsavedModifications = true
+                n.handleAsyncSave()
+                n.statusString = "Tags removed"
+            }
+            n.clearMarks()
+            n.updateShownNotes()
             break
 
         case "ct":
@@ -563,12 +1253,87 @@ func (n *NotesGui) executeCommand(g *gocui.Gui, v *gocui.View) error {
             n.statusString = "Tags cleared from note"
             break
 
+        case "f":
+            num, err := strconv.Atoi(strings.Join(parts[1:], ""))
+            if err != nil {
+                num = 1
+            }
+            _, err = g.SetCurrentView(n.mainView())
+            if err != nil {
+                return err
+            }
+            return n.followLinkNum(g, num)
+
+        case "mv":
+            if n.selectedNote == nil {
+                n.statusString = "Could not find note"
+                break
+            }
+            id, err := strconv.ParseUint(strings.Join(parts[1:], ""), 10, 32)
+            if err != nil {
+                n.statusString = "Give id of the new parent or 0 for top level"
+                break
+            }
+            var parent *Note
+            if id != 0 {
+                parent = n.Notes.FindNote(uint(id))
+                if parent == nil {
+                    n.statusString = "Could not find parent note"
+                    break
+                }
+            }
+            err = n.Notes.MoveNote(n.selectedNote, parent)
+            if err != nil {
+                n.statusString = err.Error()
+                break
+            }
+            n.unsavedModifications = true
+            n.handleAsyncSave()
+            n.updateShownNotes()
+            n.statusString = "Note moved"
+            break
+
+        case "c":
+            if n.selectedNote == nil {
+                n.statusString = "Could not find note"
+                break
+            }
+            if n.selectedNote.AddComment(n.Notes.GetCurrentUser(), strings.Join(parts[1:], " ")) {
+                n.unsavedModifications = true
+                n.handleAsyncSave()
+                n.statusString = "Comment added"
+            }
+            break
+
+        case "as":
+            if n.selectedNote == nil {
+                n.statusString = "Could not find note"
+                break
+            }
+            who, err := n.Notes.ResolveUser(strings.Join(parts[1:], " "))
+            if err != nil {
+                n.statusString = err.Error()
+                break
+            }
+            if len(who) == 0 {
+                n.selectedNote.Assignee = ""
+                n.statusString = "Assignee removed from note"
+            } else {
+                n.selectedNote.Assignee = who
+                n.statusString = "Note assigned to " + who
+            }
+            n.unsavedModifications = true
+            n.handleAsyncSave()
+            n.updateShownNotes()
+            break
+
         case "p":
             if !n.Config.UsePriority {
                 return nil
             }
 
-            if n.selectedNote == nil {
+            notes := n.getTargetNotes()
+            if len(notes) == 0 {
                 n.statusString = "Could not find note"
                 break
             }
@@ -578,10 +1343,13 @@ func (n *NotesGui) executeCommand(g *gocui.Gui, v *gocui.View) error {
                 n.statusString = "Invalid priority given. Priority should be in range 0-5"
                 break
             }
-            n.selectedNote.Priority = uint(i)
+            for _, note := range notes {
+                note.Priority = uint(i)
+            }
+            n.clearMarks()
             n.unsavedModifications = true
             n.handleAsyncSave()
-            n.statusString = "Priority set for note"
+            n.statusString = "Priority set for " + strconv.Itoa(len(notes)) + " notes"
             break
 
         case "d":
@@ -589,20 +1357,74 @@ func (n *NotesGui) executeCommand(g *gocui.Gui, v *gocui.View) error {
                 return nil
             }
 
-            if n.selectedNote == nil {
+            notes := n.getTargetNotes()
+            if len(notes) == 0 {
                 n.statusString = "Could not find note"
                 break
             }
-            dueStr := strings.Join(parts[1:], "")
-            due, err := time.Parse(n.Config.DueFormat, dueStr)
+            due, err := ParseDate(strings.Join(parts[1:], " "), n.Config.DueFormat)
             if err != nil {
-                n.statusString = "Invalid due date format. Please use " + n.Config.DueFormat
+                n.statusString = err.Error()
+                break
+            }
+            for _, note := range notes {
+                note.Due = due
+            }
+            n.clearMarks()
+            n.unsavedModifications = true
+            n.handleAsyncSave()
+            n.statusString = "Due date set for " + strconv.Itoa(len(notes)) + " notes"
+            break
+
+        case "est":
+            notes := n.getTargetNotes()
+            if len(notes) == 0 {
+                n.statusString = "Could not find note"
+                break
+            }
+
+            var estimate Estimate
+            if len(parts) > 1 && parts[1] != "none" {
+                var err error
+                estimate, err = ParseEstimate(strings.Join(parts[1:], ""))
+                if err != nil {
+                    n.statusString = err.Error()
+                    break
+                }
+            }
+            for _, note := range notes {
+                note.Estimate = estimate
+            }
+            n.clearMarks()
+            n.unsavedModifications = true
+            n.handleAsyncSave()
+            n.statusString = "Estimate set for " + strconv.Itoa(len(notes)) + " notes"
+            break
+
+        case "df":
+            notes := n.getTargetNotes()
+            if len(notes) == 0 {
+                n.statusString = "Could not find note"
                 break
             }
-            n.selectedNote.Due = due
+
+            var scheduled time.Time
+            if len(parts) > 1 && parts[1] != "none" {
+                var err error
+                scheduled, err = ParseDate(strings.Join(parts[1:], " "), n.Config.DueFormat)
+                if err != nil {
+                    n.statusString = err.Error()
+                    break
+                }
+            }
+            for _, note := range notes {
+                note.Scheduled = scheduled
+            }
+            n.clearMarks()
             n.unsavedModifications = true
             n.handleAsyncSave()
-            n.statusString = "Due date set for note"
+            n.updateShownNotes()
+            n.statusString = "Start date set for " + strconv.Itoa(len(notes)) + " notes"
             break
 
         default:
@@ -611,7 +1433,7 @@ func (n *NotesGui) executeCommand(g *gocui.Gui, v *gocui.View) error {
             }
     }
 
-    _, err := g.SetCurrentView(LIST_VIEW)
+    _, err := g.SetCurrentView(n.mainView())
     if err != nil {
         return err
     }
@@ -628,7 +1450,7 @@ func (n *NotesGui) showHelp(g *gocui.Gui) error {
 
     err = g.SetKeybinding(HELP_VIEW, gocui.KeyEsc, gocui.ModNone, func(g *gocui.Gui, v *gocui.View) error {
         g.DeleteView(HELP_VIEW)
-        g.SetCurrentView(LIST_VIEW)
+        g.SetCurrentView(n.mainView())
         n.update(g)
         return nil
     })
@@ -646,22 +1468,46 @@ func (n *NotesGui) showHelp(g *gocui.Gui) error {
     fmt.Fprintln(v, ":wq - Save and quit")
     fmt.Fprintln(v, "<j> / <k> - Move up and down")
     fmt.Fprintln(v, "<h> / <l> - Move left and right between tags")
-    fmt.Fprintln(v, "a - Add new note")
-    fmt.Fprintln(v, "D - Delete selected note")
+    fmt.Fprintln(v, "<H> / <L> - Move up and down in the tag hierarchy")
+    fmt.Fprintln(v, "a - Add new note, pick template with <j> / <k> and <enter>")
+    fmt.Fprintln(v, "D - Delete selected or marked notes")
+    fmt.Fprintln(v, "m - Mark / unmark selected note")
+    fmt.Fprintln(v, "V - Start visual mode to mark notes with <j> / <k>, press again to keep them marked")
+    fmt.Fprintln(v, "<space> - Toggle selected or marked notes done")
+    fmt.Fprintln(v, "s - Change selected or marked notes to next status")
+    fmt.Fprintln(v, "t - Start / stop timer for selected note")
+    fmt.Fprintln(v, "<esc> - Clear marks")
     fmt.Fprintln(v, "e - Edit selected note")
     fmt.Fprintln(v, "<enter> - Show note details / content")
     fmt.Fprintln(v, "G - Go to bottom of the list")
     fmt.Fprintln(v, "u - Open URLs in note in browser")
-    fmt.Fprintln(v, ":a <note> - Quick add note")
-    fmt.Fprintln(v, ":at <tag1>,<tag2> - Add tags to selected note")
-    fmt.Fprintln(v, ":rt <tag1>,<tag2> - Remove tags from selected note")
+    fmt.Fprintln(v, "T - Toggle tree view of sub notes")
+    fmt.Fprintln(v, "z - Collapse / expand sub notes of selected note in tree view")
+    fmt.Fprintln(v, ":mv <id> - Move selected note under note with given id, 0 for top level")
+    fmt.Fprintln(v, "f / :f <n> - Follow first or n:th [[link]] in selected note")
+    fmt.Fprintln(v, "b - Go back to the note where link was followed from")
+    fmt.Fprintln(v, ":a <note> - Quick add note, estimate can be given with \"est:2h\"")
+    fmt.Fprintln(v, ":est <estimate> - Set estimate such as 2h or 3pts for selected or marked notes")
+    fmt.Fprintln(v, ":at <tag1>,<tag2> - Add tags to selected or marked notes")
+    fmt.Fprintln(v, ":rt <tag1>,<tag2> - Remove tags from selected or marked notes")
     fmt.Fprintln(v, ":ct - Clear tags from selected note")
+    fmt.Fprintln(v, "c / :c <comment> - Add comment for selected note")
+    fmt.Fprintln(v, ":as <who> - Assign selected note, use \"me\" for yourself and empty to unassign")
+    fmt.Fprintln(v, "A - Show only notes assigned to me")
+    fmt.Fprintln(v, "J - Jump to journal note of today, it is created if needed")
+    fmt.Fprintln(v, "C - Show calendar, select day with <h> / <j> / <k> / <l> and month with <H> / <L>")
+    fmt.Fprintln(v, "<enter> - Filter notes by the day selected in calendar, <C> / <esc> to clear")
+    fmt.Fprintln(v, "B - Toggle board view")
+    fmt.Fprintln(v, "<tab> - Change board columns between status, priority and due")
+    fmt.Fprintln(v, "<h> / <l> - Move between columns in board view")
+    fmt.Fprintln(v, "<H> / <L> - Move selected or marked notes to previous / next column in board view")
     if n.Config.UsePriority {
-       fmt.Fprintln(v, ":p <prio> - Set priority for selected note")
+       fmt.Fprintln(v, ":p <prio> - Set priority for selected or marked notes")
     }
     if n.Config.UseDue {
-        fmt.Fprintln(v, ":d <due> - Set due date for selected note")
+        fmt.Fprintln(v, ":d <due> - Set due date for selected or marked notes")
     }
+    fmt.Fprintln(v, ":df <when> - Hide selected or marked notes until given date, \"none\" to clear")
     fmt.Fprintln(v, "/<search> - Search for notes. Press <enter> to finish, <esc> to exit")
     fmt.Fprintln(v, "<F2> - Show also done notes")
     if n.Config.UsePriority {
@@ -675,16 +1521,28 @@ func (n *NotesGui) showHelp(g *gocui.Gui) error {
     fmt.Fprintln(v, "<F7> - Order notes by created")
     fmt.Fprintln(v, "<F8> - Order notes by updated")
     fmt.Fprintln(v, "<F9> - Toggle note categorization")
+    fmt.Fprintln(v, "<F10> - Show also deferred notes")
 
     g.SetCurrentView(HELP_VIEW)
     return nil
 }
 
 func (n *NotesGui) cancelCommand(g *gocui.Gui, v *gocui.View) error {
+    if n.templatePickerShown {
+        return n.closeTemplatePicker(g, v)
+    }
+    n.pendingTemplate = nil
+
+    if len(n.cmd) == 0 {
+        n.clearMarks()
+        if n.calendarShown || !n.dueFilter.IsZero() {
+            return n.closeCalendar(g, v)
+        }
+    }
     n.cmd = ""
     n.searchStr = ""
     n.updateShownNotes()
-    _, err := g.SetCurrentView(LIST_VIEW)
+    _, err := g.SetCurrentView(n.mainView())
     if err != nil {
         return err
     }
@@ -697,6 +1555,22 @@ func (n *NotesGui) toggleShowDone(g *gocui.Gui, v *gocui.View) error {
     return n.update(g)
 }
 
+func (n *NotesGui) toggleShowDeferred(g *gocui.Gui, v *gocui.View) error {
+    n.showDeferred = !n.showDeferred
+    n.updateShownNotes()
+    return n.update(g)
+}
+
+func (n *NotesGui) toggleShowMine(g *gocui.Gui, v *gocui.View) error {
+    n.showMine = !n.showMine
+    if n.showMine && len(n.Notes.GetCurrentUser()) == 0 {
+        n.showMine = false
+        n.statusString = "Could not resolve current user. Please set your name in configuration"
+    }
+    n.updateShownNotes()
+    return n.update(g)
+}
+
 func (n *NotesGui) hasOrderColumn(col string) bool {
     for _, c := range n.sortColumns {
         if c == col {
@@ -776,8 +1650,11 @@ func (n *NotesGui) toggleCategory(g *gocui.Gui, v *gocui.View) error {
     } else if n.category == "prio" {
         n.category = "due"
     } else if n.category == "due" {
+        n.category = "status"
+    } else {
         n.category = ""
     }
+    n.updateShownNotes()
     return n.update(g)
 }
 
@@ -792,6 +1669,12 @@ func (n *NotesGui) updateListView(g *gocui.Gui) error {
     if len(n.tagFilter) > 0 {
         v.Title = n.tagFilter
     }
+    if n.showMine {
+        v.Title += " (mine)"
+    }
+    if !n.dueFilter.IsZero() {
+        v.Title += " (due " + n.dueFilter.Format(n.Config.DueFormat) + ")"
+    }
 
     notesRendered := false
     if len(n.category) == 0 {
@@ -812,6 +1695,11 @@ func (n *NotesGui) updateListView(g *gocui.Gui) error {
         fmt.Fprintln(v, "No notes")
     }
 
+    if notesRendered {
+        fmt.Fprintln(v, "")
+        fmt.Fprintln(v, color.New(color.FgHiBlack).Sprint(GetEstimateSummary(n.shownNotes)))
+    }
+
     return nil
 }
 
@@ -819,16 +1707,63 @@ func (n *NotesGui) printNotes(v *gocui.View, notes []*Note) bool {
     notesRendered := false
     for _, note := range notes {
         notesRendered = true
+        line := n.getNoteLine(note)
+        marked := false
+        for i, shown := range n.shownNotes {
+            if shown.Id == note.Id {
+                marked = n.isMarked(i, note)
+                break
+            }
+        }
+
+        if marked {
+            line = "* " + line
+        }
+
         if n.selectedNote != nil && n.selectedNote.Id == note.Id {
             c := color.New(color.Bold).Add(color.BgWhite).Add(color.FgBlack)
-            c.Fprintln(v, note.GetStatusAndTitle())
+            c.Fprintln(v, line)
+            continue
+        }
+
+        if marked {
+            c := color.New(color.FgHiCyan)
+            c.Fprintln(v, line)
+            continue
+        }
+
+        // Blocked notes are dimmed
+        if !note.Done && n.Notes.IsBlocked(note) {
+            c := color.New(color.FgHiBlack)
+            c.Fprintln(v, line)
             continue
         }
-        fmt.Fprintln(v, note.GetStatusAndTitle())
+        fmt.Fprintln(v, line)
     }
     return notesRendered
 }
 
+func (n *NotesGui) getNoteLine(note *Note) (string) {
+    line := note.GetStatusAndTitle()
+    if n.isTreeShown() {
+        marker := "  "
+        if len(n.Notes.GetChildren(note)) > 0 {
+            if n.collapsed[note.Id] {
+                marker = "+ "
+            } else {
+                marker = "- "
+            }
+        }
+        line = strings.Repeat("  ", n.depths[note.Id]) + marker + line
+    }
+
+    progress := n.Notes.GetProgressString(note)
+    if len(progress) > 0 {
+        line += " " + progress
+    }
+    return line
+}
+
 func (n *NotesGui) updatePreviewView(g *gocui.Gui) error {
     pv, err := g.View(PREVIEW_VIEW)
     if err != nil {
@@ -840,6 +1775,7 @@ func (n *NotesGui) updatePreviewView(g *gocui.Gui) error {
     if n.selectedNote != nil && !n.showNoteContent {
         pv.Title = "Details"
         fmt.Fprintln(pv, bold.Sprint("ID:       "), n.selectedNote.Id)
+        fmt.Fprintln(pv, bold.Sprint("Status:   "), n.Notes.GetStatus(n.selectedNote))
         if n.Config.UsePriority {
             c := GetPriorityColor(n.selectedNote)
             fmt.Fprintln(pv, bold.Sprint("Priority: "), c.Sprint(n.selectedNote.Priority))
@@ -851,32 +1787,130 @@ func (n *NotesGui) updatePreviewView(g *gocui.Gui) error {
             }
         }
 
+        if !n.selectedNote.Estimate.IsZero() {
+            fmt.Fprintln(pv, bold.Sprint("Estimate: "), n.selectedNote.Estimate.String())
+        }
+
+        if len(n.selectedNote.TimeEntries) > 0 {
+            fmt.Fprintln(pv, bold.Sprint("Tracked:  "), FormatDuration(n.selectedNote.GetTrackedTime().Round(time.Minute)))
+        }
+
+        if !n.selectedNote.Scheduled.IsZero() {
+            fmt.Fprintln(pv, bold.Sprint("Starts:   "), n.selectedNote.Scheduled.Format(n.Config.DueFormat))
+        }
+
         if len(n.selectedNote.Tags) > 0 {
             fmt.Fprintln(pv, bold.Sprint("Tags:     "), strings.Join(n.selectedNote.Tags, ", "))
         }
 
+        if len(n.selectedNote.Assignee) > 0 {
+            fmt.Fprintln(pv, bold.Sprint("Assignee: "), n.selectedNote.Assignee)
+        }
+
+        if len(n.selectedNote.Watchers) > 0 {
+            fmt.Fprintln(pv, bold.Sprint("Watchers: "), strings.Join(n.selectedNote.Watchers, ", "))
+        }
+
         noteUrls := n.selectedNote.GetUrls()
         if len(noteUrls) > 0 {
             fmt.Fprintln(pv, bold.Sprint("URLs:     "), len(noteUrls))
         }
 
+        if len(n.selectedNote.Comments) > 0 {
+            fmt.Fprintln(pv, bold.Sprint("Comments: "), len(n.selectedNote.Comments))
+        }
+
+        parent := n.Notes.FindNote(n.selectedNote.Parent)
+        if n.selectedNote.Parent != 0 && parent != nil {
+            fmt.Fprintln(pv, bold.Sprint("Parent:   "), strings.Join(GetNoteReferences([]*Note{parent}), ""))
+        }
+
+        progress := n.Notes.GetProgressString(n.selectedNote)
+        if len(progress) > 0 {
+            fmt.Fprintln(pv, bold.Sprint("Sub notes:"), progress)
+        }
+
+        if len(n.selectedNote.DependsOn) > 0 {
+            ids := []string{}
+            for _, id := range n.selectedNote.DependsOn {
+                ids = append(ids, strconv.Itoa(int(id)))
+            }
+            fmt.Fprintln(pv, bold.Sprint("Depends:  "), strings.Join(ids, ", "))
+        }
+
+        blockers := n.Notes.GetBlockers(n.selectedNote)
+        for i, ref := range GetNoteReferences(blockers) {
+            if i == 0 {
+                fmt.Fprintln(pv, bold.Sprint("Blocked by:"), ref)
+            } else {
+                fmt.Fprintln(pv, "           ", ref)
+            }
+        }
+
+        links := n.Notes.GetLinkedNotes(n.selectedNote)
+        for i, ref := range GetNoteReferences(links) {
+            if i == 0 {
+                fmt.Fprintln(pv, bold.Sprint("Links:    "), ref)
+            } else {
+                fmt.Fprintln(pv, "          ", ref)
+            }
+        }
+
+        backlinks := n.Notes.GetBacklinks(n.selectedNote)
+        for i, ref := range GetNoteReferences(backlinks) {
+            if i == 0 {
+                fmt.Fprintln(pv, bold.Sprint("Linked from:"), ref)
+            } else {
+                fmt.Fprintln(pv, "            ", ref)
+            }
+        }
+
+        for i, name := range n.selectedNote.GetAttachmentNames() {
+            if i == 0 {
+                fmt.Fprintln(pv, bold.Sprint("Files:    "), name)
+            } else {
+                fmt.Fprintln(pv, "          ", name)
+            }
+        }
+
         fmt.Fprintln(pv, bold.Sprint("Created:  "), n.selectedNote.Created.Format(n.Config.TimeFormat))
         fmt.Fprintln(pv, bold.Sprint("Updated:  "), n.selectedNote.Updated.Format(n.Config.TimeFormat))
     } else if n.selectedNote != nil {
         pv.Title = "Content"
         fmt.Fprint(pv, n.selectedNote.Content)
+
+        for _, comment := range n.selectedNote.Comments {
+            fmt.Fprint(pv, "\n\n")
+            fmt.Fprintln(pv, bold.Sprint(comment.Author), comment.Created.Format(n.Config.TimeFormat))
+            fmt.Fprint(pv, comment.Text)
+        }
     }
 
     return nil
 }
 
-func (n *NotesGui) updateCommandView(g *gocui.Gui) error {
-    cv, err := g.View(COMMAND_VIEW)
+func (n *NotesGui) updateActivityView(g *gocui.Gui) error {
+    av, err := g.View(ACTIVITY_VIEW)
     if err != nil {
         return err
     }
-    cv.Clear()
 
+    av.Clear()
+    if n.selectedNote == nil {
+        return nil
+    }
+
+    for _, activity := range n.selectedNote.Activity {
+        line := activity.Time.Format(n.Config.TimeFormat) + " " + activity.Action
+        if len(activity.Author) > 0 {
+            line += " (" + activity.Author + ")"
+        }
+        fmt.Fprintln(av, line)
+    }
+    return nil
+}
+
+func (n *NotesGui) updateCommandView(g *gocui.Gui) error {
     line := ""
 
     if len(n.cmd) > 0 {
@@ -886,11 +1920,45 @@ func (n *NotesGui) updateCommandView(g *gocui.Gui) error {
         n.statusString = ""
     }
 
+    n.commandLine = line
+    return n.renderCommandView(g)
+}
+
+// Renders the command bar with the latest command or status and the info
+// about marks, running timer and order
+func (n *NotesGui) renderCommandView(g *gocui.Gui) error {
+    cv, err := g.View(COMMAND_VIEW)
+    if err != nil {
+        return err
+    }
+    cv.Clear()
+
+    line := n.commandLine
+    infoStr := ""
+    note, entry := n.Notes.GetRunningTimer()
+    if entry != nil {
+        d := entry.GetDuration() / time.Second
+        infoStr += fmt.Sprintf("#%v %d:%02d:%02d ", note.Id, d / 3600, (d / 60) % 60, d % 60)
+    }
+    marked := n.countMarked()
+    if n.visualMode {
+        infoStr = "-- VISUAL -- " + infoStr
+    }
+    if marked > 0 {
+        infoStr += strconv.Itoa(marked) + " marked "
+    }
+
     if len(n.sortColumns) > 0 {
+        infoStr += "O:" + strings.Join(n.sortColumns, ",")
+    }
+
+    if len(infoStr) > 0 {
         maxX, _ := g.Size()
-        sortStr := "O:" + strings.Join(n.sortColumns, ",")
-        spaces := maxX - len(line) - len(sortStr) - 2
-        line += strings.Repeat(" ", spaces) + sortStr
+        spaces := maxX - len(line) - len(infoStr) - 2
+        if spaces < 1 {
+            spaces = 1
+        }
+        line += strings.Repeat(" ", spaces) + infoStr
     }
 
     fmt.Fprintln(cv, line)
@@ -900,7 +1968,7 @@ func (n *NotesGui) updateCommandView(g *gocui.Gui) error {
 func (n *NotesGui) update(g *gocui.Gui) error {
     g.Cursor = false
     if len(n.cmd) == 0 {
-        _, verr := g.SetCurrentView(LIST_VIEW)
+        _, verr := g.SetCurrentView(n.mainView())
         if verr != nil {
             return verr
         }
@@ -916,11 +1984,37 @@ func (n *NotesGui) update(g *gocui.Gui) error {
         return err
     }
 
+    err = n.updateActivityView(g)
+    if err != nil {
+        return err
+    }
+
     err = n.updateCommandView(g)
     if err != nil {
         return err
     }
 
+    if n.boardMode {
+        err = n.updateBoardView(g)
+        if err != nil {
+            return err
+        }
+    }
+
+    if n.calendarShown {
+        err = n.updateCalendarView(g)
+        if err != nil {
+            return err
+        }
+    }
+
+    if n.templatePickerShown {
+        err = n.updateTemplateView(g)
+        if err != nil {
+            return err
+        }
+    }
+
     return nil
 }
 
@@ -0,0 +1,128 @@
+package main
+
+import (
+    "errors"
+    "strconv"
+)
+
+func (n *Notes) GetChildren(note *Note) ([]*Note) {
+    var ret []*Note
+    for i, _ := range n.notes {
+        child := &n.notes[i]
+        if child.Parent == note.Id {
+            ret = append(ret, child)
+        }
+    }
+    return ret
+}
+
+// Returns true if the note is somewhere under the note with given id
+func (n *Notes) IsDescendant(note *Note, ancestorId uint) (bool) {
+    visited := map[uint]bool{}
+    for note != nil && note.Parent != 0 && !visited[note.Id] {
+        if note.Parent == ancestorId {
+            return true
+        }
+        visited[note.Id] = true
+        note = n.FindNote(note.Parent)
+    }
+    return false
+}
+
+// Moves the note with its sub notes under new parent. Nil parent moves the
+// note to top level.
+func (n *Notes) MoveNote(note *Note, parent *Note) (error) {
+    if parent == nil {
+        note.Parent = 0
+        return nil
+    }
+
+    if parent.Id == note.Id || n.IsDescendant(parent, note.Id) {
+        return errors.New("Note can't be moved under itself or its sub notes")
+    }
+
+    note.Parent = parent.Id
+    return nil
+}
+
+// Returns count of done and all notes under the note
+func (n *Notes) GetProgress(note *Note) (int, int) {
+    return n.getProgress(note, map[uint]bool{note.Id: true})
+}
+
+// Counts progress of the sub notes skipping notes already visited in case
+// the parents form a cycle
+func (n *Notes) getProgress(note *Note, visited map[uint]bool) (int, int) {
+    done := 0
+    total := 0
+    for _, child := range n.GetChildren(note) {
+        if visited[child.Id] {
+            continue
+        }
+        visited[child.Id] = true
+
+        total++
+        if child.Done {
+            done++
+        }
+        childDone, childTotal := n.getProgress(child, visited)
+        done += childDone
+        total += childTotal
+    }
+    return done, total
+}
+
+// Returns progress of the note in format (done/total) or empty string if
+// the note does not have sub notes
+func (n *Notes) GetProgressString(note *Note) (string) {
+    done, total := n.GetProgress(note)
+    if total == 0 {
+        return ""
+    }
+    return "(" + strconv.Itoa(done) + "/" + strconv.Itoa(total) + ")"
+}
+
+// Orders notes so that sub notes follow their parents. Notes whose parent is
+// not in the given notes are shown at top level. Order of the siblings is
+// kept. Returns also the depth of each note in the tree.
+func (n *Notes) OrderAsTree(notes []*Note) ([]*Note, map[uint]int) {
+    included := map[uint]bool{}
+    for _, note := range notes {
+        included[note.Id] = true
+    }
+
+    children := map[uint][]*Note{}
+    var roots []*Note
+    for _, note := range notes {
+        if note.Parent != 0 && included[note.Parent] && note.Parent != note.Id {
+            children[note.Parent] = append(children[note.Parent], note)
+        } else {
+            roots = append(roots, note)
+        }
+    }
+
+    var ret []*Note
+    depths := map[uint]int{}
+    var visit func(note *Note, depth int)
+    visit = func(note *Note, depth int) {
+        if _, ok := depths[note.Id]; ok {
+            return
+        }
+        depths[note.Id] = depth
+        ret = append(ret, note)
+        for _, child := range children[note.Id] {
+            visit(child, depth + 1)
+        }
+    }
+
+    for _, note := range roots {
+        visit(note, 0)
+    }
+
+    // Notes whose parents form a cycle have no root, so the first note of
+    // each cycle is shown as one
+    for _, note := range notes {
+        visit(note, 0)
+    }
+    return ret, depths
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+    "reflect"
+    "testing"
+)
+
+func TestGetProgress(t *testing.T) {
+    n := newTestNotes(
+        Note{Id: 1},
+        Note{Id: 2, Parent: 1, Done: true},
+        Note{Id: 3, Parent: 1},
+        Note{Id: 4, Parent: 3, Done: true},
+        Note{Id: 5},
+        // Parents forming a cycle
+        Note{Id: 6, Parent: 7},
+        Note{Id: 7, Parent: 6, Done: true},
+        Note{Id: 8, Parent: 8},
+    )
+
+    tests := []struct {
+        id uint
+        done int
+        total int
+    }{
+        {1, 2, 3},
+        {3, 1, 1},
+        {5, 0, 0},
+        {6, 1, 1},
+        {7, 0, 1},
+        {8, 0, 0},
+    }
+
+    for _, test := range tests {
+        done, total := n.GetProgress(n.FindNote(test.id))
+        if done != test.done || total != test.total {
+            t.Errorf("GetProgress(%v) = %v/%v, want %v/%v", test.id, done, total, test.done, test.total)
+        }
+    }
+}
+
+func TestOrderAsTree(t *testing.T) {
+    n := newTestNotes(
+        Note{Id: 1, Parent: 2},
+        Note{Id: 2, Parent: 1},
+        Note{Id: 3},
+        Note{Id: 4, Parent: 3},
+        Note{Id: 5, Parent: 5},
+        Note{Id: 6, Parent: 2},
+    )
+
+    notes, depths := n.OrderAsTree(n.GetNotes())
+    ids := getIds(notes)
+    expected := []uint{3, 4, 5, 1, 2, 6}
+    if !reflect.DeepEqual(ids, expected) {
+        t.Fatalf("OrderAsTree() = %v, want %v", ids, expected)
+    }
+
+    expectedDepths := map[uint]int{1: 0, 2: 1, 3: 0, 4: 1, 5: 0, 6: 2}
+    if !reflect.DeepEqual(depths, expectedDepths) {
+        t.Errorf("OrderAsTree() depths = %v, want %v", depths, expectedDepths)
+    }
+}
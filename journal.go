@@ -0,0 +1,89 @@
+package main
+
+import (
+    "sort"
+    "strconv"
+    "time"
+)
+
+const JOURNAL_TAG = "journal"
+
+// Returns title of the journal note of the given day
+func (n *Notes) GetJournalTitle(day time.Time) (string) {
+    return "Journal " + day.Format(n.config.DueFormat)
+}
+
+// Returns journal notes ordered from the newest to the oldest. Journal notes
+// are the notes tagged with the journal tag.
+func (n *Notes) GetJournalNotes() ([]*Note) {
+    var ret []*Note
+    for _, note := range n.GetNotes() {
+        if note.HasExactTag(JOURNAL_TAG) {
+            ret = append(ret, note)
+        }
+    }
+
+    sort.SliceStable(ret, func(i, j int) bool {
+        return ret[i].Created.After(ret[j].Created)
+    })
+    return ret
+}
+
+// Returns the journal note of the given day or nil if there is none. Notes
+// are matched by the day they were created so that editing the title or
+// changing the date format does not create another journal note.
+func (n *Notes) FindJournalNote(day time.Time) (*Note) {
+    start := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, time.Local)
+    end := start.AddDate(0, 0, 1)
+
+    var ret *Note
+    for _, note := range n.GetJournalNotes() {
+        if note.Created.Before(start) || !note.Created.Before(end) {
+            continue
+        }
+        // The first journal note of the day is used
+        ret = note
+    }
+    return ret
+}
+
+// Returns content for a new journal note listing the notes due on the day
+// and the notes completed on the previous day
+func (n *Notes) GetJournalTemplate(day time.Time) (string) {
+    ret := n.GetJournalTitle(day) + "\n\n"
+
+    ret += "## Due today\n\n"
+    due := n.FilterNotesByDueDay(RoundTimeToDay(day), n.FilterDoneNotes(n.GetNotes()))
+    n.OrderNotes([]string{"prio"}, due)
+    for _, note := range due {
+        ret += "- [ ] " + note.GetTitle() + " (#" + strconv.Itoa(int(note.Id)) + ")\n"
+    }
+    if len(due) == 0 {
+        ret += "Nothing due\n"
+    }
+
+    ret += "\n## Done yesterday\n\n"
+    start := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, time.Local)
+    done := n.GetCompletedNotes(n.GetNotes(), start.AddDate(0, 0, -1), start)
+    for _, note := range done {
+        ret += "- [x] " + note.GetTitle() + " (#" + strconv.Itoa(int(note.Id)) + ")\n"
+    }
+    if len(done) == 0 {
+        ret += "Nothing done\n"
+    }
+
+    ret += "\n## Notes\n\n"
+    return ret
+}
+
+// Returns the journal note of the given day creating it if it does not
+// exist yet. Returns also whether the note was created.
+func (n *Notes) GetOrCreateJournalNote(day time.Time) (*Note, bool) {
+    note := n.FindJournalNote(day)
+    if note != nil {
+        return note, false
+    }
+
+    id := n.AddNote(Note{Content: n.GetJournalTemplate(day), Priority: n.config.DefaultPriority, Tags: []string{JOURNAL_TAG}})
+    return n.FindNote(id), true
+}
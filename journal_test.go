@@ -0,0 +1,44 @@
+package main
+
+import (
+    "testing"
+    "time"
+)
+
+func TestFindJournalNote(t *testing.T) {
+    today := RoundTimeToDay(time.Now())
+    n := newTestNotes(
+        Note{Id: 1, Content: "Standup notes", Tags: []string{JOURNAL_TAG}, Created: today.Add(8 * time.Hour)},
+        Note{Id: 2, Content: "Journal later", Tags: []string{JOURNAL_TAG}, Created: today.Add(10 * time.Hour)},
+        Note{Id: 3, Content: "Journal yesterday", Tags: []string{JOURNAL_TAG}, Created: today.Add(-time.Hour)},
+        Note{Id: 4, Content: "Journal " + today.Format("02.01.2006"), Created: today.Add(7 * time.Hour)},
+    )
+
+    tests := []struct {
+        day time.Time
+        id uint
+    }{
+        {today.Add(12 * time.Hour), 1},
+        {today.AddDate(0, 0, -1), 3},
+        {today.AddDate(0, 0, -2), 0},
+        {today.AddDate(0, 0, 1), 0},
+    }
+
+    for _, test := range tests {
+        note := n.FindJournalNote(test.day)
+        var id uint
+        if note != nil {
+            id = note.Id
+        }
+        if id != test.id {
+            t.Errorf("FindJournalNote(%v) = %v, want %v", test.day, id, test.id)
+        }
+    }
+
+    // Changing the date format does not create another journal note
+    n.config.DueFormat = "2006-01-02"
+    note, created := n.GetOrCreateJournalNote(today.Add(12 * time.Hour))
+    if created || note.Id != 1 {
+        t.Errorf("GetOrCreateJournalNote() = %v, %v, want existing note 1", note.Id, created)
+    }
+}
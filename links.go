@@ -0,0 +1,79 @@
+package main
+
+import (
+    "regexp"
+    "strconv"
+    "strings"
+)
+
+// Matches wiki style links such as [[42]] or [[Note title]]
+var linkRegexp = regexp.MustCompile(`\[\[([^\[\]\n]+)\]\]`)
+
+// Returns targets of the wiki style links in the note content
+func (n *Note) GetLinks() ([]string) {
+    var ret []string
+    for _, match := range linkRegexp.FindAllStringSubmatch(n.Content, -1) {
+        ret = append(ret, strings.Trim(match[1], " "))
+    }
+    return ret
+}
+
+// Finds note the link target refers to. Links with id are preferred so they
+// keep working even if the title of the note changes.
+func (n *Notes) ResolveLink(target string) (*Note) {
+    id, err := strconv.ParseUint(strings.TrimPrefix(target, "#"), 10, 32)
+    if err == nil {
+        return n.FindNote(uint(id))
+    }
+
+    for i, _ := range n.notes {
+        note := &n.notes[i]
+        if strings.EqualFold(strings.Trim(note.GetTitle(), " "), target) {
+            return note
+        }
+    }
+    return nil
+}
+
+// Returns notes linked from the note
+func (n *Notes) GetLinkedNotes(note *Note) ([]*Note) {
+    var ret []*Note
+    found := map[uint]bool{}
+    for _, target := range note.GetLinks() {
+        linked := n.ResolveLink(target)
+        if linked == nil || linked.Id == note.Id || found[linked.Id] {
+            continue
+        }
+        found[linked.Id] = true
+        ret = append(ret, linked)
+    }
+    return ret
+}
+
+// Returns notes that link to the note
+func (n *Notes) GetBacklinks(note *Note) ([]*Note) {
+    var ret []*Note
+    for i, _ := range n.notes {
+        other := &n.notes[i]
+        if other.Id == note.Id {
+            continue
+        }
+
+        for _, linked := range n.GetLinkedNotes(other) {
+            if linked.Id == note.Id {
+                ret = append(ret, other)
+                break
+            }
+        }
+    }
+    return ret
+}
+
+// Returns ids and titles of the notes for printing
+func GetNoteReferences(notes []*Note) ([]string) {
+    var ret []string
+    for _, note := range notes {
+        ret = append(ret, "#" + strconv.Itoa(int(note.Id)) + " " + note.GetTitle())
+    }
+    return ret
+}
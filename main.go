@@ -2,6 +2,7 @@ package main
 
 import (
     "fmt"
+    "encoding/json"
     "log"
     "os"
     "time"
@@ -20,7 +21,99 @@ func getNoteFromArg(arg string, n *Notes) (*Note) {
     return n.FindNote(uint(id))
 }
 
-func handleListArgs(args []string, printer *NotesPrinter) {
+// Notes selected for a command with id list or query
+type noteSelection struct {
+    notes []*Note
+    args []string
+    dryRun bool
+    confirmed bool
+}
+
+// Selects notes for bulk commands. Notes are selected either with list of
+// ids and id ranges such as "3,5,10-14" given as the first argument or with
+// a query given with -q. Rest of the arguments are returned in the selection.
+func getNotesFromArgs(args []string, n *Notes, c *Configuration) (*noteSelection, error) {
+    sel := noteSelection{}
+    queryStr := ""
+    for i := 0; i < len(args); i++ {
+        arg := args[i]
+        if (arg == "--query" || arg == "-q") && len(args) > i + 1 {
+            queryStr = args[i+1]
+            i++
+        } else if arg == "--dry-run" || arg == "-n" {
+            sel.dryRun = true
+        } else if arg == "--yes" || arg == "-y" {
+            sel.confirmed = true
+        } else {
+            sel.args = append(sel.args, arg)
+        }
+    }
+
+    if len(queryStr) > 0 {
+        query, err := ParseQuery(queryStr, c.DueFormat)
+        if err != nil {
+            return nil, err
+        }
+        sel.notes = n.QueryNotes(query, n.GetNotes())
+        if len(sel.notes) == 0 {
+            return nil, errors.New("No notes matched the query")
+        }
+        return &sel, nil
+    }
+
+    if len(sel.args) < 1 {
+        return nil, errors.New("Give note id")
+    }
+
+    ids, ranged, err := ParseIdList(sel.args[0])
+    if err != nil {
+        return nil, err
+    }
+    sel.args = sel.args[1:]
+
+    for _, id := range ids {
+        note := n.FindNote(id)
+        if note == nil {
+            // Ranges may span over deleted notes
+            if ranged[id] {
+                continue
+            }
+            return nil, errors.New("Could not find note with id " + strconv.Itoa(int(id)))
+        }
+        sel.notes = append(sel.notes, note)
+    }
+
+    if len(sel.notes) == 0 {
+        return nil, errors.New("Could not find notes with given ids")
+    }
+    return &sel, nil
+}
+
+// Prints the selected notes for dry run and asks confirmation if there are
+// many notes selected. Returns true if the command should be applied.
+func confirmSelection(sel *noteSelection, c *Configuration, action string) (bool, error) {
+    if !sel.dryRun && (sel.confirmed || len(sel.notes) <= c.BulkConfirmThreshold) {
+        return true, nil
+    }
+
+    fmt.Printf("The following %v notes would be affected by %v:\n", len(sel.notes), action)
+    for _, note := range sel.notes {
+        fmt.Printf("  %v\t%v\n", note.Id, note.GetTitle())
+    }
+
+    if sel.dryRun {
+        return false, nil
+    }
+
+    for {
+        ret, err := YesNoQuestion("Are you sure you want to continue [y/n]? ")
+        if err == nil {
+            return ret, nil
+        }
+    }
+}
+
+func handleListArgs(args []string, printer *NotesPrinter, n *Notes, c *Configuration) (error) {
     for i, arg := range args {
         if (arg == "--order" || arg == "-o") && len(args) > i + 1 {
             col := args[i+1]
@@ -42,10 +135,218 @@ func handleListArgs(args []string, printer *NotesPrinter) {
             printer.TagFilter = args[i+1]
         }
 
+        if (arg == "--assignee" || arg == "-a") && len(args) > i + 1 {
+            printer.AssigneeFilter = args[i+1]
+            printer.ShowAssignee = true
+        }
+
+        if arg == "--tree" {
+            printer.Tree = true
+        }
+
+        if arg == "--actionable" {
+            printer.SkipBlocked = true
+        }
+
+        if arg == "--all" {
+            printer.SkipDeferred = false
+        }
+
+        if arg == "--mine" {
+            who, err := n.ResolveUser("me")
+            if err != nil {
+                return err
+            }
+            printer.AssigneeFilter = who
+        }
+
+        if (arg == "--query" || arg == "-q") && len(args) > i + 1 {
+            query, err := ParseQuery(args[i+1], c.DueFormat)
+            if err != nil {
+                return err
+            }
+            printer.Query = query
+        }
+
         if arg == "-la" {
             printer.PrintDetails = true
         }
     }
+    return nil
+}
+
+// Handles reports of the notes
+func handleReportArgs(args []string, n *Notes, c *Configuration) (bool, error) {
+    if len(args) == 0 {
+        return false, errors.New("Give report type")
+    }
+
+    command := args[0]
+    args = args[1:]
+
+    sinceStr := "monday"
+    untilStr := ""
+    by := "tag"
+    asCsv := false
+    author := ""
+    allNotes := false
+    var query *Query
+    for i, arg := range args {
+        if len(args) > i + 1 {
+            switch(arg) {
+                case "--since":
+                    sinceStr = args[i+1]
+                    break
+                case "--until":
+                    untilStr = args[i+1]
+                    break
+                case "--by":
+                    by = args[i+1]
+                    break
+                case "-q":
+                    fallthrough
+                case "--query":
+                    q, err := ParseQuery(args[i+1], c.DueFormat)
+                    if err != nil {
+                        return false, err
+                    }
+                    query = q
+                    break
+            }
+        }
+
+        switch(arg) {
+            case "--csv":
+                asCsv = true
+                break
+            case "--mine":
+                var err error
+                author, err = n.ResolveUser("me")
+                if err != nil {
+                    return false, err
+                }
+                break
+            case "--all":
+                allNotes = true
+                break
+        }
+    }
+
+    since, err := ParseSince(sinceStr, c.DueFormat)
+    if err != nil {
+        return false, err
+    }
+
+    var until time.Time
+    if len(untilStr) > 0 {
+        until, err = ParseSince(untilStr, c.DueFormat)
+        if err != nil {
+            return false, err
+        }
+    }
+
+    notes := n.GetNotes()
+    if query != nil {
+        notes = n.QueryNotes(query, notes)
+    }
+
+    printer := NewNotesPrinter(c)
+    switch command {
+        case "estimates":
+            if !allNotes {
+                notes = n.OnlyDoneNotes(notes)
+            }
+            keys, report := n.GetEstimateReport(notes)
+            printer.PrintEstimateReport(keys, report, asCsv)
+            return false, nil
+
+        case "time":
+            keys, totals, err := n.GetTimeReport(notes, since, until, by, author)
+            if err != nil {
+                return false, err
+            }
+            printer.PrintTimeReport(keys, totals, n.GetTotalTime(notes, since, until, author), by, asCsv)
+            return false, nil
+    }
+    return false, errors.New("Invalid report " + command)
+}
+
+// Handles bulk management of tags in all notes
+func handleTagsArgs(args []string, n *Notes, c *Configuration) (bool, error) {
+    command := args[0]
+    args = args[1:]
+
+    switch command {
+        case "rename":
+            if len(args) != 2 {
+                return false, errors.New("Give the old and the new tag")
+            }
+
+            count, err := n.RenameTag(args[0], args[1])
+            if err != nil {
+                return false, err
+            }
+            fmt.Printf("Renamed tag \"%v\" to \"%v\" in %v notes\n", args[0], args[1], count)
+            return count > 0, nil
+
+        case "merge":
+            if len(args) < 2 {
+                return false, errors.New("Give the tags to merge and the tag to merge them into")
+            }
+
+            into := args[len(args)-1]
+            sources := args[:len(args)-1]
+            count, err := n.MergeTags(sources, into)
+            if err != nil {
+                return false, err
+            }
+            fmt.Printf("Merged tags \"%v\" into \"%v\" in %v notes\n", strings.Join(sources, "\", \""), into, count)
+            return count > 0, nil
+
+        case "delete":
+            if len(args) != 1 {
+                return false, errors.New("Give the tag to delete")
+            }
+
+            count := n.DeleteTag(args[0])
+            fmt.Printf("Deleted tag \"%v\" from %v notes\n", args[0], count)
+            return count > 0, nil
+
+        case "alias":
+            if len(args) != 2 {
+                return false, errors.New("Give the alias and the tag it refers to")
+            }
+
+            if c.TagAliases == nil {
+                c.TagAliases = map[string]string{}
+            }
+            c.TagAliases[args[0]] = args[1]
+            err := c.Save()
+            if err != nil {
+                return false, err
+            }
+            fmt.Printf("Tag \"%v\" is now counted as \"%v\"\n", args[0], args[1])
+            return false, nil
+
+        case "unalias":
+            if len(args) != 1 {
+                return false, errors.New("Give the alias to remove")
+            }
+
+            _, ok := c.TagAliases[args[0]]
+            if !ok {
+                return false, errors.New("Could not find alias " + args[0])
+            }
+            delete(c.TagAliases, args[0])
+            err := c.Save()
+            if err != nil {
+                return false, err
+            }
+            fmt.Printf("Removed tag alias \"%v\"\n", args[0])
+            return false, nil
+    }
+
+    return false, errors.New("Invalid tags command: " + command)
 }
 
 func handleArgs(args []string, n *Notes, c *Configuration) (bool, error) {
@@ -70,8 +371,12 @@ func handleArgs(args []string, n *Notes, c *Configuration) (bool, error) {
                 return false, errors.New("Missing note content")
             }
 
-            content := strings.Join(args, " ")
-            note := Note{Content: content, Priority: c.DefaultPriority}
+            content, estimate, err := ExtractEstimate(strings.Join(args, " "))
+            if err != nil {
+                return false, err
+            }
+
+            note := Note{Content: content, Priority: c.DefaultPriority, Estimate: estimate}
             id := n.AddNote(note)
             fmt.Printf("Added new note \"%v\" with id %v\n", note.GetTitle(), id)
             return true, nil
@@ -80,18 +385,64 @@ func handleArgs(args []string, n *Notes, c *Configuration) (bool, error) {
             fallthrough
         case "add":
             note := Note{Priority: c.DefaultPriority}
+            var template *Template
+            for i, arg := range args {
+                if (arg == "-T" || arg == "--template") && len(args) > i + 1 {
+                    t, err := FindTemplate(args[i+1])
+                    if err != nil {
+                        return false, err
+                    }
+                    template = t
+                }
+            }
+
+            if template != nil {
+                values := map[string]string{}
+                for _, prompt := range template.GetPrompts() {
+                    value, err := Question(prompt + ": ")
+                    if err != nil {
+                        return false, err
+                    }
+                    values[prompt] = value
+                }
+
+                t, err := n.ApplyTemplate(template, values)
+                if err != nil {
+                    return false, err
+                }
+                note = t
+            }
+
             updated, err := note.EditInEditor()
             if err != nil {
                 return false, err
             }
 
+            // Notes from templates are added only if the template was edited
             if updated {
                 id := n.AddNote(note)
                 fmt.Printf("Added new note \"%v\" with id %v\n", note.GetTitle(), id)
+            } else if template != nil {
+                fmt.Println("Template was not edited, note not added")
             }
 
             return updated, nil
 
+        case "templates":
+            templates, err := LoadTemplates()
+            if err != nil {
+                return false, err
+            }
+
+            folder, err := GetTemplateFolder()
+            if err != nil {
+                return false, err
+            }
+
+            printer := NewNotesPrinter(c)
+            printer.PrintTemplates(templates, folder)
+            return false, nil
+
         // Clear all notes
         case "clear":
             for {
@@ -112,7 +463,10 @@ func handleArgs(args []string, n *Notes, c *Configuration) (bool, error) {
         case "ls":
             printer := NewNotesPrinter(c)
             printer.SkipDone = false
-            handleListArgs(args, &printer)
+            err := handleListArgs(args, &printer, n, c)
+            if err != nil {
+                return false, err
+            }
             printer.Print(n)
             return false, nil
 
@@ -121,7 +475,11 @@ func handleArgs(args []string, n *Notes, c *Configuration) (bool, error) {
             fallthrough
         case "todo":
             printer := NewNotesPrinter(c)
-            handleListArgs(args, &printer)
+            printer.SkipDeferred = true
+            err := handleListArgs(args, &printer, n, c)
+            if err != nil {
+                return false, err
+            }
             printer.ShowDone = false
             printer.SkipDone = true
             printer.Print(n)
@@ -150,17 +508,26 @@ func handleArgs(args []string, n *Notes, c *Configuration) (bool, error) {
         case "done":
             fallthrough
         case "md":
-            if len(args) < 1 {
-                return false, errors.New("Give note id")
+            sel, err := getNotesFromArgs(args, n, c)
+            if err != nil {
+                return false, err
             }
 
-            note := getNoteFromArg(args[0], n)
-            if note == nil {
-                return false, errors.New("Could not find note with id")
+            ok, err := confirmSelection(sel, c, "marking done")
+            if !ok {
+                return false, err
+            }
+
+            for _, note := range sel.notes {
+                n.SetDone(note, true)
+                fmt.Printf("Note \"%v\" with id %v is now done\n", note.GetTitle(), note.Id)
             }
 
-            note.Done = true
-            fmt.Printf("Note \"%v\" with id %v is now done\n", note.GetTitle(), note.Id)
+            for _, note := range sel.notes {
+                for _, dependent := range n.GetUnblockedDependents(note) {
+                    fmt.Printf("Note \"%v\" with id %v is now actionable\n", dependent.GetTitle(), dependent.Id)
+                }
+            }
             return true, nil
 
         case "e":
@@ -183,20 +550,69 @@ func handleArgs(args []string, n *Notes, c *Configuration) (bool, error) {
             if !c.UsePriority {
                 break
             }
-            if len(args) < 2 {
-                return false, errors.New("Give note id and new priority")
+            sel, err := getNotesFromArgs(args, n, c)
+            if err != nil {
+                return false, err
             }
 
-            note := getNoteFromArg(args[0], n)
-            if note == nil {
-                return false, errors.New("Could not find note with id")
+            if len(sel.args) < 1 {
+                return false, errors.New("Give note id and new priority")
             }
 
-            prio, err := strconv.ParseUint(args[1], 0, 32)
+            prio, err := strconv.ParseUint(sel.args[0], 0, 32)
             if err != nil || prio > 5 {
                 return false, errors.New("Invalid priority given. Priority should be in range 0-5")
             }
-            note.Priority = uint(prio)
+
+            ok, err := confirmSelection(sel, c, "setting priority")
+            if !ok {
+                return false, err
+            }
+
+            for _, note := range sel.notes {
+                note.Priority = uint(prio)
+                fmt.Printf("Priority of note %v set to %v\n", note.Id, prio)
+            }
+            return true, nil
+
+        case "st":
+            fallthrough
+        case "status":
+            sel, err := getNotesFromArgs(args, n, c)
+            if err != nil {
+                return false, err
+            }
+
+            if len(sel.args) < 1 {
+                return false, errors.New("Give note id and new status. Statuses are: " + strings.Join(c.Statuses, ", "))
+            }
+
+            status, found := c.FindStatus(strings.Join(sel.args, " "))
+            if !found {
+                return false, errors.New("Invalid status. Statuses are: " + strings.Join(c.Statuses, ", "))
+            }
+
+            ok, err := confirmSelection(sel, c, "setting status")
+            if !ok {
+                return false, err
+            }
+
+            for _, note := range sel.notes {
+                err = n.SetStatus(note, status)
+                if err != nil {
+                    return false, err
+                }
+                fmt.Printf("Status of note %v set to %v\n", note.Id, status)
+            }
+
+            for _, note := range sel.notes {
+                if !note.Done {
+                    continue
+                }
+                for _, dependent := range n.GetUnblockedDependents(note) {
+                    fmt.Printf("Note \"%v\" with id %v is now actionable\n", dependent.GetTitle(), dependent.Id)
+                }
+            }
             return true, nil
 
         case "s":
@@ -212,30 +628,47 @@ func handleArgs(args []string, n *Notes, c *Configuration) (bool, error) {
             }
 
             printer := NewNotesPrinter(c)
-            printer.PrintFullNote(note)
+            printer.PrintFullNote(n, note)
 
             return false, nil
 
         case "rm":
             fallthrough
         case "remove":
-            if len(args) < 1 {
-                return false, errors.New("Give note id")
+            sel, err := getNotesFromArgs(args, n, c)
+            if err != nil {
+                return false, err
             }
 
-            note := getNoteFromArg(args[0], n)
-            if note == nil {
-                return false, errors.New("Could not find note with id")
+            ok, err := confirmSelection(sel, c, "removing")
+            if !ok {
+                return false, err
             }
 
-            id := note.Id
-            title := note.GetTitle()
-            err := n.DeleteNote(note.Id)
-            if err != nil {
-                return false, err
+            // Deleting notes invalidates the note pointers so collect the
+            // ids first
+            var ids []uint
+            for _, note := range sel.notes {
+                ids = append(ids, note.Id)
             }
 
-            fmt.Printf("Removed note \"%v\" with id %v\n", title, id)
+            for _, id := range ids {
+                note := n.FindNote(id)
+                if note == nil {
+                    continue
+                }
+                title := note.GetTitle()
+                children := len(n.GetChildren(note))
+                err := n.DeleteNote(id)
+                if err != nil {
+                    return false, err
+                }
+
+                fmt.Printf("Removed note \"%v\" with id %v\n", title, id)
+                if children > 0 {
+                    fmt.Printf("Moved %v sub notes to the parent of the removed note\n", children)
+                }
+            }
             return true, nil
 
         case "ct":
@@ -256,80 +689,894 @@ func handleArgs(args []string, n *Notes, c *Configuration) (bool, error) {
         case "t":
             fallthrough
         case "tag":
-            if len(args) < 2 {
+            sel, err := getNotesFromArgs(args, n, c)
+            if err != nil {
+                return false, err
+            }
+
+            if len(sel.args) < 1 {
                 return false, errors.New("Give note id and the tag")
             }
 
-            note := getNoteFromArg(args[0], n)
-            if note == nil {
-                return false, errors.New("Could not find note with id")
+            ok, err := confirmSelection(sel, c, "adding tag")
+            if !ok {
+                return false, err
             }
 
-            ret := note.AddTag(args[1])
-            if ret {
-                fmt.Printf("Added tag \"%v\" for note %v\n", args[1], note.Id)
-            } else {
-                fmt.Printf("Note %v already had tag \"%v\"\n", note.Id, args[1])
+            modified := false
+            tag := sel.args[0]
+            for _, note := range sel.notes {
+                if note.AddTag(tag) {
+                    fmt.Printf("Added tag \"%v\" for note %v\n", tag, note.Id)
+                    modified = true
+                } else {
+                    fmt.Printf("Note %v already had tag \"%v\"\n", note.Id, tag)
+                }
             }
-            return ret, nil
+            return modified, nil
 
         case "rt":
             fallthrough
         case "rtag":
-            if len(args) < 2 {
+            sel, err := getNotesFromArgs(args, n, c)
+            if err != nil {
+                return false, err
+            }
+
+            if len(sel.args) < 1 {
                 return false, errors.New("Give note id and the tag")
             }
 
-            note := getNoteFromArg(args[0], n)
-            if note == nil {
-                return false, errors.New("Could not find note with id")
+            ok, err := confirmSelection(sel, c, "removing tag")
+            if !ok {
+                return false, err
             }
 
-            ret := note.RemoveTag(args[1])
-            if ret {
-                fmt.Printf("Removed tag \"%v\" for note %v\n", args[1], note.Id)
-            } else {
-                fmt.Printf("Note %v does not have tag \"%v\"\n", note.Id, args[1])
+            modified := false
+            tag := sel.args[0]
+            for _, note := range sel.notes {
+                if note.RemoveTag(tag) {
+                    fmt.Printf("Removed tag \"%v\" for note %v\n", tag, note.Id)
+                    modified = true
+                } else {
+                    fmt.Printf("Note %v does not have tag \"%v\"\n", note.Id, tag)
+                }
             }
-            return ret, nil
+            return modified, nil
 
-        case "tags":
-            tags := n.GetTags()
-            if len(tags) == 0 {
-                fmt.Println("No tags in any notes")
-                return false, nil
+        case "assign":
+            if len(args) < 2 {
+                return false, errors.New("Give note id and the assignee")
             }
 
-            fmt.Println("The following tags were found from notes:")
-            for tag, v := range tags {
-                fmt.Printf("%v (%v notes)\n", tag, v)
+            note := getNoteFromArg(args[0], n)
+            if note == nil {
+                return false, errors.New("Could not find note with id")
             }
-            return false, nil
 
-        case "d":
-            fallthrough
+            who, err := n.ResolveUser(strings.Join(args[1:], " "))
+            if err != nil {
+                return false, err
+            }
+            if len(who) == 0 {
+                return false, errors.New("Give the assignee")
+            }
+
+            note.Assignee = who
+            fmt.Printf("Note %v is now assigned to %v\n", note.Id, who)
+            return true, nil
+
+        case "unassign":
+            if len(args) < 1 {
+                return false, errors.New("Give note id")
+            }
+
+            note := getNoteFromArg(args[0], n)
+            if note == nil {
+                return false, errors.New("Could not find note with id")
+            }
+
+            if len(note.Assignee) == 0 {
+                fmt.Printf("Note %v is not assigned to anyone\n", note.Id)
+                return false, nil
+            }
+
+            note.Assignee = ""
+            fmt.Printf("Removed assignee from note %v\n", note.Id)
+            return true, nil
+
+        case "watch":
+            if len(args) < 1 {
+                return false, errors.New("Give note id")
+            }
+
+            note := getNoteFromArg(args[0], n)
+            if note == nil {
+                return false, errors.New("Could not find note with id")
+            }
+
+            who := "me"
+            if len(args) > 1 {
+                who = strings.Join(args[1:], " ")
+            }
+            who, err := n.ResolveUser(who)
+            if err != nil {
+                return false, err
+            }
+
+            ret := note.AddWatcher(who)
+            if ret {
+                fmt.Printf("Added watcher \"%v\" for note %v\n", who, note.Id)
+            } else {
+                fmt.Printf("Note %v is already watched by \"%v\"\n", note.Id, who)
+            }
+            return ret, nil
+
+        case "unwatch":
+            if len(args) < 1 {
+                return false, errors.New("Give note id")
+            }
+
+            note := getNoteFromArg(args[0], n)
+            if note == nil {
+                return false, errors.New("Could not find note with id")
+            }
+
+            who := "me"
+            if len(args) > 1 {
+                who = strings.Join(args[1:], " ")
+            }
+            who, err := n.ResolveUser(who)
+            if err != nil {
+                return false, err
+            }
+
+            ret := note.RemoveWatcher(who)
+            if ret {
+                fmt.Printf("Removed watcher \"%v\" from note %v\n", who, note.Id)
+            } else {
+                fmt.Printf("Note %v is not watched by \"%v\"\n", note.Id, who)
+            }
+            return ret, nil
+
+        case "tags":
+            if len(args) > 0 {
+                return handleTagsArgs(args, n, c)
+            }
+
+            tags := n.GetTagTree()
+            if len(tags) == 0 {
+                fmt.Println("No tags in any notes")
+                return false, nil
+            }
+
+            fmt.Println("The following tags were found from notes:")
+            printer := NewNotesPrinter(c)
+            printer.PrintTagTree(tags)
+            return false, nil
+
+        case "d":
+            fallthrough
         case "due":
             if !c.UseDue {
                 break
             }
 
+            sel, err := getNotesFromArgs(args, n, c)
+            if err != nil {
+                return false, err
+            }
+
+            if len(sel.args) < 1 {
+                return false, errors.New("Give note id and the due date, for example tomorrow, friday, 3d or date in format " + c.DueFormat)
+            }
+
+            due, err := ParseDate(strings.Join(sel.args, " "), c.DueFormat)
+            if err != nil {
+                return false, err
+            }
+
+            ok, err := confirmSelection(sel, c, "setting due date")
+            if !ok {
+                return false, err
+            }
+
+            for _, note := range sel.notes {
+                note.Due = due
+                fmt.Printf("Due date set for note %v\n", note.Id)
+            }
+            return true, nil
+
+        case "est":
+            fallthrough
+        case "estimate":
+            sel, err := getNotesFromArgs(args, n, c)
+            if err != nil {
+                return false, err
+            }
+
+            if len(sel.args) < 1 {
+                return false, errors.New("Give note id and the estimate, for example 2h or 3pts")
+            }
+
+            var estimate Estimate
+            if sel.args[0] != "none" {
+                estimate, err = ParseEstimate(sel.args[0])
+                if err != nil {
+                    return false, err
+                }
+            }
+
+            ok, err := confirmSelection(sel, c, "setting estimate")
+            if !ok {
+                return false, err
+            }
+
+            for _, note := range sel.notes {
+                note.Estimate = estimate
+                if estimate.IsZero() {
+                    fmt.Printf("Estimate removed from note %v\n", note.Id)
+                } else {
+                    fmt.Printf("Estimate of note %v set to %v\n", note.Id, estimate)
+                }
+            }
+            return true, nil
+
+        case "defer":
+            sel, err := getNotesFromArgs(args, n, c)
+            if err != nil {
+                return false, err
+            }
+
+            if len(sel.args) < 1 {
+                return false, errors.New("Give note id and the start date, for example monday, 3d or date in format " + c.DueFormat)
+            }
+
+            var scheduled time.Time
+            if sel.args[0] != "none" {
+                scheduled, err = ParseDate(strings.Join(sel.args, " "), c.DueFormat)
+                if err != nil {
+                    return false, err
+                }
+            }
+
+            ok, err := confirmSelection(sel, c, "setting start date")
+            if !ok {
+                return false, err
+            }
+
+            for _, note := range sel.notes {
+                note.Scheduled = scheduled
+                if scheduled.IsZero() {
+                    fmt.Printf("Start date removed from note %v\n", note.Id)
+                } else {
+                    fmt.Printf("Note %v deferred until %v\n", note.Id, scheduled.Format(c.DueFormat))
+                }
+            }
+            return true, nil
+
+        case "mv":
+            fallthrough
+        case "move":
+            sel, err := getNotesFromArgs(args, n, c)
+            if err != nil {
+                return false, err
+            }
+
+            if len(sel.args) < 1 {
+                return false, errors.New("Give note id and the id of the new parent or 0 for top level")
+            }
+
+            var parent *Note
+            if sel.args[0] != "0" {
+                parent = getNoteFromArg(sel.args[0], n)
+                if parent == nil {
+                    return false, errors.New("Could not find parent note with id")
+                }
+            }
+
+            ok, err := confirmSelection(sel, c, "moving")
+            if !ok {
+                return false, err
+            }
+
+            for _, note := range sel.notes {
+                err := n.MoveNote(note, parent)
+                if err != nil {
+                    return false, err
+                }
+
+                if parent == nil {
+                    fmt.Printf("Moved note %v to top level\n", note.Id)
+                } else {
+                    fmt.Printf("Moved note %v under note %v\n", note.Id, parent.Id)
+                }
+            }
+            return true, nil
+
+        case "block":
+            if len(args) < 2 {
+                return false, errors.New("Give note id and the id of the note it depends on")
+            }
+
+            note := getNoteFromArg(args[0], n)
+            dep := getNoteFromArg(args[1], n)
+            if note == nil || dep == nil {
+                return false, errors.New("Could not find note with id")
+            }
+
+            ret, err := n.AddDependency(note, dep)
+            if err != nil {
+                return false, err
+            }
+
+            if ret {
+                fmt.Printf("Note %v now depends on note %v\n", note.Id, dep.Id)
+            } else {
+                fmt.Printf("Note %v already depends on note %v\n", note.Id, dep.Id)
+            }
+            return ret, nil
+
+        case "unblock":
             if len(args) < 2 {
-                return false, errors.New("Give note id and the due date in format " + c.DueFormat)
+                return false, errors.New("Give note id and the id of the note it depends on")
             }
+
             note := getNoteFromArg(args[0], n)
             if note == nil {
                 return false, errors.New("Could not find note with id")
             }
 
-            dueStr := strings.Join(args[1:], " ")
-            due, err := time.Parse(c.DueFormat, dueStr)
+            id, err := strconv.ParseUint(args[1], 0, 32)
             if err != nil {
-                return false, errors.New("Invalid due date format used. Please use " + c.DueFormat)
+                return false, errors.New("Invalid note id given")
             }
-            note.Due = due
-            fmt.Printf("Due date set for note %v\n", note.Id)
+
+            ret := n.RemoveDependency(note, uint(id))
+            if ret {
+                fmt.Printf("Note %v no longer depends on note %v\n", note.Id, id)
+            } else {
+                fmt.Printf("Note %v does not depend on note %v\n", note.Id, id)
+            }
+            return ret, nil
+
+        case "comment":
+            if len(args) < 2 {
+                return false, errors.New("Give note id and the comment")
+            }
+
+            note := getNoteFromArg(args[0], n)
+            if note == nil {
+                return false, errors.New("Could not find note with id")
+            }
+
+            ret := note.AddComment(n.GetCurrentUser(), strings.Join(args[1:], " "))
+            if ret {
+                fmt.Printf("Added comment for note %v\n", note.Id)
+            }
+            return ret, nil
+
+        case "remind":
+            if len(args) < 1 {
+                return false, errors.New("Give note id")
+            }
+
+            note := getNoteFromArg(args[0], n)
+            if note == nil {
+                return false, errors.New("Could not find note with id")
+            }
+
+            if len(args) == 1 {
+                printer := NewNotesPrinter(c)
+                printer.PrintReminders(note)
+                return false, nil
+            }
+
+            if args[1] == "clear" {
+                note.Reminders = nil
+                fmt.Printf("Removed reminders of note %v\n", note.Id)
+                return true, nil
+            }
+
+            reminder, err := ParseReminder(strings.Join(args[1:], " "), c.TimeFormat, c.DueFormat)
+            if err != nil {
+                return false, err
+            }
+
+            if reminder.Relative && note.Due.IsZero() {
+                fmt.Printf("Note %v has no due date. Reminder is delivered once the due date is set\n", note.Id)
+            }
+            n.AddReminder(note, reminder)
+            fmt.Printf("Added reminder %v for note %v\n", reminder.Describe(c.TimeFormat), note.Id)
+            return true, nil
+
+        case "snooze":
+            if len(args) < 2 {
+                return false, errors.New("Give note id and the time to snooze, for example 2h")
+            }
+
+            note := getNoteFromArg(args[0], n)
+            if note == nil {
+                return false, errors.New("Could not find note with id")
+            }
+
+            d, err := ParseDuration(args[1])
+            if err != nil {
+                return false, err
+            }
+
+            n.SnoozeReminder(note, d)
+            fmt.Printf("Note %v is snoozed until %v\n", note.Id, time.Now().Add(d).Format(c.TimeFormat))
             return true, nil
 
+        case "daemon":
+            daemon := ReminderDaemon{Notes: n, Config: c, Interval: time.Minute}
+            command := c.ReminderCommand
+            fifo := c.ReminderFifo
+            logFile := c.ReminderLog
+            quiet := false
+            for i, arg := range args {
+                if len(args) > i + 1 {
+                    switch(arg) {
+                        case "--interval":
+                            d, err := ParseDuration(args[i+1])
+                            if err != nil {
+                                return false, err
+                            }
+                            daemon.Interval = d
+                            break
+                        case "--command":
+                            command = args[i+1]
+                            break
+                        case "--fifo":
+                            fifo = args[i+1]
+                            break
+                        case "--log":
+                            logFile = args[i+1]
+                            break
+                    }
+                }
+                if arg == "--quiet" {
+                    quiet = true
+                }
+            }
+
+            if !quiet {
+                daemon.Sinks = append(daemon.Sinks, NewLogSink(os.Stdout))
+            }
+            if len(logFile) > 0 {
+                f, err := os.OpenFile(logFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+                if err != nil {
+                    return false, err
+                }
+                defer f.Close()
+                daemon.Sinks = append(daemon.Sinks, NewLogSink(f))
+            }
+            if len(command) > 0 {
+                daemon.Sinks = append(daemon.Sinks, &CommandSink{Command: command})
+            }
+            if len(fifo) > 0 {
+                daemon.Sinks = append(daemon.Sinks, &FifoSink{Path: fifo})
+            }
+            return false, daemon.Run()
+
+        case "serve":
+            server := NotesServer{Notes: n, Config: c, Addr: "127.0.0.1:7777", SaveDelay: 5 * time.Second}
+            newToken := false
+            for i, arg := range args {
+                if len(args) > i + 1 {
+                    switch(arg) {
+                        case "--addr":
+                            server.Addr = args[i+1]
+                            break
+                        case "--save-delay":
+                            d, err := ParseDuration(args[i+1])
+                            if err != nil {
+                                return false, err
+                            }
+                            server.SaveDelay = d
+                            break
+                    }
+                }
+                if arg == "--new-token" {
+                    newToken = true
+                }
+            }
+
+            if len(c.ApiToken) == 0 || newToken {
+                token, err := GenerateApiToken()
+                if err != nil {
+                    return false, err
+                }
+                c.ApiToken = token
+                err = c.Save()
+                if err != nil {
+                    return false, err
+                }
+                fmt.Println("Generated new API token " + token)
+            }
+
+            server.Token = c.ApiToken
+            fmt.Printf("Serving notes at http://%v, use the api_token in configuration for authentication\n", server.Addr)
+            return false, server.Run()
+
+        case "start":
+            if len(args) < 1 {
+                return false, errors.New("Give note id")
+            }
+
+            note := getNoteFromArg(args[0], n)
+            if note == nil {
+                return false, errors.New("Could not find note with id")
+            }
+
+            stopped, entry := n.GetRunningTimer()
+            err := n.StartTimer(note)
+            if err != nil {
+                return false, err
+            }
+
+            if stopped != nil {
+                fmt.Printf("Stopped timer of note %v after %v\n", stopped.Id, FormatDuration(entry.GetDuration().Round(time.Minute)))
+            }
+            fmt.Printf("Started timer for note %v\n", note.Id)
+            return true, nil
+
+        case "stop":
+            note, entry := n.StopTimer()
+            if note == nil {
+                return false, errors.New("No timer is running")
+            }
+
+            fmt.Printf("Stopped timer of note %v after %v\n", note.Id, FormatDuration(entry.GetDuration().Round(time.Minute)))
+            return true, nil
+
+        case "report":
+            return handleReportArgs(args, n, c)
+
+        case "comments":
+            if len(args) < 1 {
+                return false, errors.New("Give note id")
+            }
+
+            note := getNoteFromArg(args[0], n)
+            if note == nil {
+                return false, errors.New("Could not find note with id")
+            }
+
+            printer := NewNotesPrinter(c)
+            printer.PrintComments(note)
+            return false, nil
+
+        case "attach":
+            if len(args) < 2 {
+                return false, errors.New("Give note id and the file to attach")
+            }
+
+            note := getNoteFromArg(args[0], n)
+            if note == nil {
+                return false, errors.New("Could not find note with id")
+            }
+
+            attachment, err := n.AttachFile(note, strings.Join(args[1:], " "))
+            if err != nil {
+                return false, err
+            }
+            fmt.Printf("Attached file \"%v\" (%v) for note %v\n", attachment.Name, FormatSize(attachment.Size), note.Id)
+            return true, nil
+
+        case "attachments":
+            if len(args) < 1 {
+                return false, errors.New("Give note id")
+            }
+
+            note := getNoteFromArg(args[0], n)
+            if note == nil {
+                return false, errors.New("Could not find note with id")
+            }
+
+            printer := NewNotesPrinter(c)
+            printer.PrintAttachments(note)
+            return false, nil
+
+        case "fetch":
+            if len(args) < 2 {
+                return false, errors.New("Give note id and the attachment number")
+            }
+
+            note := getNoteFromArg(args[0], n)
+            if note == nil {
+                return false, errors.New("Could not find note with id")
+            }
+
+            attachment, _, err := note.GetAttachment(args[1])
+            if err != nil {
+                return false, err
+            }
+
+            dir := "."
+            if len(args) > 2 {
+                dir = args[2]
+            }
+
+            path, err := n.FetchAttachment(attachment, dir)
+            if err != nil {
+                return false, err
+            }
+            fmt.Printf("Downloaded attachment to %v\n", path)
+            return false, nil
+
+        case "detach":
+            if len(args) < 2 {
+                return false, errors.New("Give note id and the attachment number")
+            }
+
+            note := getNoteFromArg(args[0], n)
+            if note == nil {
+                return false, errors.New("Could not find note with id")
+            }
+
+            _, idx, err := note.GetAttachment(args[1])
+            if err != nil {
+                return false, err
+            }
+
+            attachment, err := n.DetachFile(note, idx)
+            if err != nil {
+                return false, err
+            }
+            fmt.Printf("Removed attachment \"%v\" from note %v\n", attachment.Name, note.Id)
+            return true, nil
+
+        case "log":
+            if len(args) < 1 {
+                return false, errors.New("Give note id")
+            }
+
+            note := getNoteFromArg(args[0], n)
+            if note == nil {
+                return false, errors.New("Could not find note with id")
+            }
+
+            printer := NewNotesPrinter(c)
+            printer.PrintActivity(note)
+            return false, nil
+
+        case "activity":
+            sinceStr := "7d"
+            for i, arg := range args {
+                if arg == "--since" && len(args) > i + 1 {
+                    sinceStr = args[i+1]
+                }
+            }
+
+            since, err := ParseSince(sinceStr, c.DueFormat)
+            if err != nil {
+                return false, err
+            }
+
+            printer := NewNotesPrinter(c)
+            printer.PrintActivitySummary(n.GetActivitySince(since), since)
+            return false, nil
+
+        // Open or create journal note of today
+        case "today":
+            note, created := n.GetOrCreateJournalNote(time.Now())
+            if created {
+                fmt.Printf("Created journal note \"%v\" with id %v\n", note.GetTitle(), note.Id)
+            }
+
+            updated, err := note.EditInEditor()
+            if err != nil {
+                return created, err
+            }
+            return created || updated, nil
+
+        case "journal":
+            last := 7
+            for i, arg := range args {
+                if arg == "--last" && len(args) > i + 1 {
+                    days, err := strconv.Atoi(args[i+1])
+                    if err != nil || days < 1 {
+                        return false, errors.New("Invalid number of days given")
+                    }
+                    last = days
+                }
+            }
+
+            printer := NewNotesPrinter(c)
+            err := handleListArgs(args, &printer, n, c)
+            if err != nil {
+                return false, err
+            }
+            printer.SortColumns = []string{"-created"}
+            printer.ShowCreated = true
+
+            since := RoundTimeToDay(time.Now()).AddDate(0, 0, -(last - 1))
+            var notes []*Note
+            for _, note := range n.GetJournalNotes() {
+                if !note.Created.Before(since) {
+                    notes = append(notes, note)
+                }
+            }
+            printer.PrintNotes(n, notes)
+            return false, nil
+
+        case "done-log":
+            sinceStr := "yesterday"
+            untilStr := ""
+            notes := n.GetNotes()
+            for i, arg := range args {
+                if len(args) <= i + 1 {
+                    continue
+                }
+                switch(arg) {
+                    case "--since":
+                        sinceStr = args[i+1]
+                        break
+                    case "--until":
+                        untilStr = args[i+1]
+                        break
+                    case "-q":
+                        fallthrough
+                    case "--query":
+                        query, err := ParseQuery(args[i+1], c.DueFormat)
+                        if err != nil {
+                            return false, err
+                        }
+                        notes = n.QueryNotes(query, notes)
+                        break
+                }
+            }
+
+            since, err := ParseSince(sinceStr, c.DueFormat)
+            if err != nil {
+                return false, err
+            }
+
+            var until time.Time
+            if len(untilStr) > 0 {
+                until, err = ParseSince(untilStr, c.DueFormat)
+                if err != nil {
+                    return false, err
+                }
+            }
+
+            n.WriteDoneLog(os.Stdout, notes, since, until)
+            return false, nil
+
+        case "burndown":
+            fromStr := "14d"
+            toStr := "today"
+            asCsv := false
+            notes := n.GetNotes()
+            for i, arg := range args {
+                if arg == "--csv" {
+                    asCsv = true
+                }
+                if len(args) <= i + 1 {
+                    continue
+                }
+                switch(arg) {
+                    case "--from":
+                        fromStr = args[i+1]
+                        break
+                    case "--to":
+                        toStr = args[i+1]
+                        break
+                    case "-q":
+                        fallthrough
+                    case "--query":
+                        query, err := ParseQuery(args[i+1], c.DueFormat)
+                        if err != nil {
+                            return false, err
+                        }
+                        notes = n.QueryNotes(query, notes)
+                        break
+                }
+            }
+
+            from, err := ParseBurndownDay(fromStr, c.DueFormat, true)
+            if err != nil {
+                return false, err
+            }
+            to, err := ParseBurndownDay(toStr, c.DueFormat, false)
+            if err != nil {
+                return false, err
+            }
+            if to.Before(from) {
+                return false, errors.New("End of the burndown is before the start")
+            }
+
+            days, start := n.GetBurndown(notes, from, to)
+            printer := NewNotesPrinter(c)
+            printer.PrintBurndown(days, start, asCsv)
+            return false, nil
+
+        case "stats":
+            weeks := 8
+            format := "text"
+            notes := n.GetNotes()
+            for i, arg := range args {
+                if len(args) <= i + 1 {
+                    continue
+                }
+                switch(arg) {
+                    case "--weeks":
+                        w, err := strconv.Atoi(args[i+1])
+                        if err != nil || w < 1 {
+                            return false, errors.New("Invalid number of weeks given")
+                        }
+                        weeks = w
+                        break
+                    case "--format":
+                        format = args[i+1]
+                        break
+                    case "-q":
+                        fallthrough
+                    case "--query":
+                        query, err := ParseQuery(args[i+1], c.DueFormat)
+                        if err != nil {
+                            return false, err
+                        }
+                        notes = n.QueryNotes(query, notes)
+                        break
+                }
+            }
+
+            stats := n.GetStats(notes, weeks, 5)
+            switch(format) {
+                case "json":
+                    out, err := json.MarshalIndent(stats, "", "  ")
+                    if err != nil {
+                        return false, err
+                    }
+                    fmt.Println(string(out))
+                    break
+                case "text":
+                    printer := NewNotesPrinter(c)
+                    printer.PrintStats(stats)
+                    break
+                default:
+                    return false, errors.New("Invalid format " + format + ". Use text or json")
+            }
+            return false, nil
+
+        case "agenda":
+            days := 7
+            if len(args) > 0 {
+                i, err := strconv.Atoi(args[0])
+                if err != nil || i < 1 {
+                    return false, errors.New("Invalid number of days given")
+                }
+                days = i
+            }
+
+            printer := NewNotesPrinter(c)
+            printer.PrintAgenda(n, days)
+            return false, nil
+
+        case "cal":
+            month := GetToday()
+            if len(args) > 0 {
+                m, err := strconv.Atoi(args[0])
+                if err != nil || m < 1 || m > 12 {
+                    return false, errors.New("Invalid month given. Month should be in range 1-12")
+                }
+                year := month.Year()
+                if len(args) > 1 {
+                    year, err = strconv.Atoi(args[1])
+                    if err != nil {
+                        return false, errors.New("Invalid year given")
+                    }
+                }
+                month = time.Date(year, time.Month(m), 1, 0, 0, 0, 0, time.UTC)
+            }
+
+            printer := NewNotesPrinter(c)
+            printer.PrintCalendar(n, month)
+            return false, nil
+
         case "h":
             fallthrough
         case "help":
@@ -354,12 +1601,18 @@ func printHelp(c *Configuration) {
     fmt.Println("GENERAL:")
     fmt.Println("h|help\t\t\tPrint this help")
     fmt.Println("config\t\t\tConfigure the look&feel")
+    fmt.Println("daemon\t\t\tDeliver reminders until stopped. Reminders are printed and")
+    fmt.Println("\t\t\tgiven to the configured command, FIFO and log file")
+    fmt.Println("\t\t\t--interval <duration> --command <cmd> --fifo <path> --log <file> --quiet")
     fmt.Println("")
     fmt.Println("ADDING / EDITING:")
-    fmt.Println("qa <note>\t\tQuickly add note with default values")
+    fmt.Println("qa <note>\t\tQuickly add note with default values. Estimate can be")
+    fmt.Println("\t\t\tgiven in the note, for example \"est:2h\"")
     fmt.Println("e|edit <id>\t\tEdit note with given id")
     fmt.Println("a|add\t\t\tAdd new note with $EDITOR")
+    fmt.Println("a|add -T <name>\t\tAdd new note from template with $EDITOR")
     fmt.Println("md|done <id>\t\tMark note done with given id")
+    fmt.Println("st|status <id> <status>\tSet status of the note, one of: " + strings.Join(c.Statuses, ", "))
     if c.UsePriority {
         fmt.Println("p|prio <id> <prio>\tSet priority of the note")
     }
@@ -367,28 +1620,105 @@ func printHelp(c *Configuration) {
     fmt.Println("rt|rtag <id> <tag>\tRemote tag from note with given id")
     fmt.Println("ct|ctags <id>\t\tRemove all tags from note with given id")
     if c.UseDue {
-       fmt.Println("d|due <id> <due>\tSet due date for note with given id")
+       fmt.Println("d|due <id> <due>\tSet due date for note with given id, for example tomorrow,")
+       fmt.Println("\t\t\tfriday, 3d or date")
     }
+    fmt.Println("est|estimate <id> <est>\tSet estimate such as 2h or 3pts for note, \"none\" to clear")
+    fmt.Println("defer <id> <when>\tHide note from todo until given date, \"none\" to clear")
+    fmt.Println("mv|move <id> <parent>\tMove note with its sub notes under another note, 0 for top level")
+    fmt.Println("block <id> <other-id>\tMark note to depend on another note")
+    fmt.Println("unblock <id> <other-id>\tRemove dependency between notes")
+    fmt.Println("comment <id> <text>\tAdd comment for note with given id")
+    fmt.Println("attach <id> <file>\tUpload file to Drive and attach it to note")
+    fmt.Println("detach <id> <n>\t\tRemove attachment with given number from note")
+    fmt.Println("remind <id> <when>\tAdd reminder for note, for example \"1h before due\", \"in 2h\"")
+    fmt.Println("\t\t\tor time. Without time shows reminders, \"clear\" removes them")
+    fmt.Println("snooze <id> <duration>\tDeliver the latest reminder again after given time, for example 2h")
+    fmt.Println("start <id>\t\tStart tracking time for note, stops the running timer")
+    fmt.Println("stop\t\t\tStop the running timer")
+    fmt.Println("assign <id> <who>\tAssign note to someone, use \"me\" for yourself")
+    fmt.Println("unassign <id>\t\tRemove assignee from note with given id")
+    fmt.Println("watch <id> [<who>]\tAdd watcher for note, defaults to yourself")
+    fmt.Println("unwatch <id> [<who>]\tRemove watcher from note, defaults to yourself")
+    fmt.Println("")
+    fmt.Println("TAGS:")
+    fmt.Println("tags rename <old> <new>\tRename tag in all notes")
+    fmt.Println("tags merge <tags> <into>\tMerge one or more tags into one tag in all notes")
+    fmt.Println("tags delete <tag>\tDelete tag from all notes")
+    fmt.Println("tags alias <alias> <tag>\tCount alias as the given tag")
+    fmt.Println("tags unalias <alias>\tRemove tag alias")
     fmt.Println("")
     fmt.Println("DELETING:")
     fmt.Println("clear\t\t\tDelete all notes")
-    fmt.Println("rm|remove <id>\t\tRemove note with given id")
+    fmt.Println("rm|remove <id>\t\tRemove note with given id. Sub notes are moved to its parent")
     fmt.Println("")
     fmt.Println("SHOWING:")
     fmt.Println("ls|list\t\t\tList all notes")
-    fmt.Println("td|todo\t\t\tList all not-done notes")
+    fmt.Println("td|todo\t\t\tList all not-done notes. Deferred notes are shown with --all")
     fmt.Println("s|show <id>\t\tShow note contents with given id")
     fmt.Println("tags\t\t\tShow all tags assigned to notes")
+    fmt.Println("templates\t\tShow templates for new notes")
     fmt.Println("u|urls <id>\t\tOpen URLs in note in browser")
+    fmt.Println("comments <id>\t\tShow comments of note with given id")
+    fmt.Println("attachments <id>\tShow attachments of note with given id")
+    fmt.Println("fetch <id> <n> [<dir>]\tDownload attachment with given number")
+    fmt.Println("log <id>\t\tShow activity log of note with given id")
+    fmt.Println("agenda [<days>]\t\tShow notes due during the next days, defaults to 7 days")
+    fmt.Println("cal [<month> [<year>]]\tShow calendar with number of notes due on each day")
+    fmt.Println("activity\t\tShow recent changes in all notes. Use --since <time> to")
+    fmt.Println("\t\t\tlimit the changes, for example 7d, 12h or yesterday")
+    fmt.Println("today\t\t\tOpen journal note of today. Note is created if it does not exist")
+    fmt.Println("journal\t\t\tList journal notes of the last 7 days. Use --last <n> to change the")
+    fmt.Println("\t\t\tdays and -la to show the notes with details")
+    fmt.Println("serve\t\t\tServe notes as JSON at 127.0.0.1:7777. Use --addr <addr> to change")
+    fmt.Println("\t\t\tthe address and --new-token to generate new API token")
+    fmt.Println("")
+    fmt.Println("REPORTS:")
+    fmt.Println("stats\t\t\tShow statistics of the notes. Use --weeks <n> to change the")
+    fmt.Println("\t\t\tweeks shown, -q <query> to limit the notes and --format json")
+    fmt.Println("done-log\t\tList notes done since yesterday as Markdown grouped by day and")
+    fmt.Println("\t\t\ttag. Use --since, --until and -q <query> to limit the notes")
+    fmt.Println("burndown\t\tShow open notes per day. Use -q <query> to limit the notes,")
+    fmt.Println("\t\t\t--from and --to to set the days (default last 14 days), for")
+    fmt.Println("\t\t\texample --from monday --to friday or 2026-10-01, and --csv")
+    fmt.Println("report time\t\tShow time tracked since monday per tag")
+    fmt.Println("--since <time>\t\tCount time since given time, for example monday, 7d or date")
+    fmt.Println("--until <time>\t\tCount time until given time")
+    fmt.Println("--by <group>\t\tGroup time by tag, note, day or user")
+    fmt.Println("--mine\t\tCount only time tracked by you")
+    fmt.Println("-q|--query <query>\tCount only notes matching the query")
+    fmt.Println("report estimates\tCompare estimated and tracked time of done notes per tag,")
+    fmt.Println("\t\t\tmost under-estimated first. Use --all to include open notes")
+    fmt.Println("--csv\t\tPrint report as CSV")
+    fmt.Println("")
+    fmt.Println("BULK COMMANDS:")
+    fmt.Println("md, status, rm, tag, rtag, prio, est, due, defer and move accept list of ids and id ranges, for")
+    fmt.Println("example \"md 3,5,10-14\", or a query instead of single id, for example")
+    fmt.Println("\"md -q 'tag:sprint12 AND NOT done'\"")
+    fmt.Println("-q|--query <query>\tSelect notes with query")
+    fmt.Println("-n|--dry-run\t\tOnly show notes that would be affected")
+    fmt.Println("-y|--yes\t\tDo not ask confirmation when many notes are affected")
+    fmt.Println("")
+    fmt.Println("Query terms: tag:<tag> prio:<n> prio<n prio>n done open blocked deferred mine")
+    fmt.Println("assignee:<who> status:<status> id:<ids> parent:<id>")
+    fmt.Println("due:<today|tomorrow|overdue|none|any|date>")
+    fmt.Println("and any text. Combine with AND, OR, NOT and parentheses.")
     fmt.Println("")
     fmt.Println("Additional parameters for listing:")
     fmt.Println("--order|-o <columns>\tComma separated list of sort columns. Has to be one of the following:")
-    fmt.Println("\t\t\tid,title,prio,created,updated,due")
+    fmt.Println("\t\t\tid,title,prio,estimate,created,updated,due,scheduled,assignee,status")
     fmt.Println("--search|-s <string>\tSearch for notes with given content")
     if c.UsePriority {
         fmt.Println("--prio|-p <int>\tSearch for notes with this or greater priority")
     }
-    fmt.Println("--tag|-t <tag>\tSearch for notes with this tag")
+    fmt.Println("--tag|-t <tag>\tSearch for notes with this tag or tags under it, for example")
+    fmt.Println("\t\t\t\"work\" matches also \"work/backend\"")
+    fmt.Println("--assignee|-a <who>\tSearch for notes assigned to given user")
+    fmt.Println("--mine\t\tSearch for notes assigned to you")
+    fmt.Println("--tree\t\tShow sub notes indented under their parents")
+    fmt.Println("--actionable\t\tHide notes blocked by other not done notes")
+    fmt.Println("--all\t\tShow also deferred notes in todo")
+    fmt.Println("--query|-q <query>\tSearch for notes matching the query")
     fmt.Println("-la\t\tPrint whole notes instead table")
 }
 
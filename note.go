@@ -19,11 +19,31 @@ type Note struct {
     Id uint `json:"id"`
     Content string `json:"content"`
     Priority uint `json:"priority"`
+    Estimate Estimate `json:"estimate"`
     Done bool `json:"done"`
+    Status string `json:"status"`
+    CompletedAt time.Time `json:"completed_at"`
     Created time.Time `json:"created"`
     Updated time.Time `json:"updated"`
     Due time.Time     `json:"due"`
+    Scheduled time.Time `json:"scheduled"`
     Tags []string `json:"tags"`
+    Assignee string `json:"assignee"`
+    Watchers []string `json:"watchers"`
+    Activity []Activity `json:"activity"`
+    Comments []Comment `json:"comments"`
+    Attachments []Attachment `json:"attachments"`
+    DependsOn []uint `json:"depends_on"`
+    Parent uint `json:"parent"`
+    Reminders []Reminder `json:"reminders"`
+    TimeEntries []TimeEntry `json:"time_entries"`
+}
+
+// Comment written for a note
+type Comment struct {
+    Author string `json:"author"`
+    Created time.Time `json:"created"`
+    Text string `json:"text"`
 }
 
 // Returns title of the note
@@ -43,7 +63,22 @@ func (n *Note) GetStatusAndTitle() (string) {
     return ret
 }
 
+// Returns true if the start date of the note has not yet arrived
+func (n *Note) IsDeferred() (bool) {
+    return !n.Scheduled.IsZero() && RoundTimeToDay(n.Scheduled).After(GetToday())
+}
+
+// Returns true if the note has the tag or any tag under it in the hierarchy
 func (n *Note) HasTag(tag string) (bool) {
+    for _, t := range n.Tags {
+        if TagMatches(t, tag) {
+            return true
+        }
+    }
+    return false
+}
+
+func (n *Note) HasExactTag(tag string) (bool) {
     for _, t := range n.Tags {
         if t == tag {
             return true
@@ -55,7 +90,7 @@ func (n *Note) HasTag(tag string) (bool) {
 func (n *Note) AddTag(tag string) (bool) {
     tagStr := strings.Trim(tag, " ")
 
-    if !n.HasTag(tagStr) {
+    if !n.HasExactTag(tagStr) {
         n.Tags = append(n.Tags, tagStr)
         return true
     }
@@ -83,6 +118,61 @@ func (n *Note) ClearTags() bool {
     return true
 }
 
+func (n *Note) IsAssignedTo(who string) (bool) {
+    return len(who) > 0 && strings.EqualFold(n.Assignee, who)
+}
+
+func (n *Note) HasWatcher(who string) (bool) {
+    for _, w := range n.Watchers {
+        if strings.EqualFold(w, who) {
+            return true
+        }
+    }
+    return false
+}
+
+func (n *Note) AddWatcher(who string) (bool) {
+    whoStr := strings.Trim(who, " ")
+    if len(whoStr) == 0 || n.HasWatcher(whoStr) {
+        return false
+    }
+    n.Watchers = append(n.Watchers, whoStr)
+    return true
+}
+
+func (n *Note) RemoveWatcher(who string) (bool) {
+    whoStr := strings.Trim(who, " ")
+    for i := 0; i < len(n.Watchers); i++ {
+        if strings.EqualFold(n.Watchers[i], whoStr) {
+            n.Watchers = append(n.Watchers[:i], n.Watchers[i+1:]...)
+            return true
+        }
+    }
+    return false
+}
+
+func (n *Note) LogActivity(author string, action string) {
+    n.Activity = append(n.Activity, Activity{Time: time.Now(), Author: author, Action: action})
+}
+
+func (n *Note) AddComment(author string, text string) (bool) {
+    textStr := strings.Trim(text, " \n")
+    if len(textStr) == 0 {
+        return false
+    }
+    n.Comments = append(n.Comments, Comment{Author: author, Created: time.Now(), Text: textStr})
+    return true
+}
+
+// Returns names and sizes of the attachments
+func (n *Note) GetAttachmentNames() ([]string) {
+    var ret []string
+    for _, a := range n.Attachments {
+        ret = append(ret, a.Name + " (" + FormatSize(a.Size) + ")")
+    }
+    return ret
+}
+
 func (n *Note) EditInEditor() (bool, error) {
     editor, ok := os.LookupEnv("EDITOR")
     if !ok {
@@ -3,7 +3,6 @@ package main
 import (
     "encoding/json"
     "fmt"
-    "io/ioutil"
     "log"
     "net/http"
     "os"
@@ -11,6 +10,7 @@ import (
     "strconv"
     "sort"
     "strings"
+    "sync"
     "time"
 
     "golang.org/x/net/context"
@@ -19,6 +19,14 @@ import (
     "google.golang.org/api/drive/v3"
 )
 
+// Returned when "me" is used but the current user can not be resolved
+var ErrUnknownUser = errors.New("Could not resolve current user. Please set your name in configuration")
+
+// Returned when the notes file has been modified in Drive after it was loaded
+var ErrNotesConflict = errors.New("Notes have been modified by someone else since they were loaded. Please try again")
+
+const notesFileFields = "id, name, md5Checksum, headRevisionId"
+
 // NOTES functionality
 type Notes struct {
     notes []Note
@@ -27,6 +35,24 @@ type Notes struct {
     file *drive.File
     max_id uint
     config *Configuration
+    user string
+    // Drive is asked for the user only once as the lookup blocks
+    userLookedUp bool
+    snapshot map[uint]Note
+    removedFiles []string
+
+    // Notes as they were last saved or loaded and the version of the
+    // remote notes they were based on
+    synced map[uint]Note
+    syncedVersion uint64
+    saveSeq uint64
+    appliedSeq uint64
+
+    // Guards the notes file and the notes stored in it
+    saveMutex sync.Mutex
+    remoteNotes []Note
+    version uint64
+    uploadedSeq uint64
 }
 
 func (n *Notes) Init(config *Configuration) (error) {
@@ -56,7 +82,7 @@ func (n *Notes) Init(config *Configuration) (error) {
 
     n.file = notes_file
 
-    err = n.reloadFromDrive()
+    err = n.reloadFromDrive(notes_file)
     if err != nil {
         return err
     }
@@ -64,18 +90,62 @@ func (n *Notes) Init(config *Configuration) (error) {
     return nil
 }
 
-func (n *Notes) AsyncSaveNotes(ch chan<- error) {
-    go func() {
-        ch <- n.SaveNotes()
-    }()
-}
-
-func (n *Notes) SaveNotes() (error) {
+// Appends activity log entries for all notes modified since the notes were
+// loaded or previously saved
+func (n *Notes) recordActivity() {
     now := time.Now()
-    for _, note := range n.notes {
+    author := ""
+    for i, _ := range n.notes {
+        note := &n.notes[i]
+        var actions []string
+        // Ids of deleted notes can be reused by new notes
+        old, ok := n.snapshot[note.Id]
+        if !ok || !sameNote(&old, note) {
+            actions = append(actions, "created")
+        } else {
+            actions = diffNotes(&old, note, n.config.DueFormat)
+        }
+
+        if len(actions) == 0 {
+            continue
+        }
+
+        if len(author) == 0 {
+            author = n.GetCurrentUser()
+        }
+
+        for _, action := range actions {
+            note.LogActivity(author, action)
+        }
         note.Updated = now
     }
-    return n.syncNotesFile()
+    n.takeSnapshot()
+}
+
+func (n *Notes) takeSnapshot() {
+    n.snapshot = map[uint]Note{}
+    for i, _ := range n.notes {
+        n.snapshot[n.notes[i].Id] = copyNote(&n.notes[i])
+    }
+}
+
+// Returns activity of all notes after given time ordered by time
+func (n *Notes) GetActivitySince(since time.Time) ([]NoteActivity) {
+    var ret []NoteActivity
+    for i, _ := range n.notes {
+        note := &n.notes[i]
+        for j, _ := range note.Activity {
+            activity := &note.Activity[j]
+            if activity.Time.After(since) {
+                ret = append(ret, NoteActivity{Note: note, Activity: activity})
+            }
+        }
+    }
+
+    sort.SliceStable(ret, func(i, j int) bool {
+        return ret[i].Activity.Time.Before(ret[j].Activity.Time)
+    })
+    return ret
 }
 
 func (n *Notes) AddNote(note Note) (uint) {
@@ -112,11 +182,17 @@ func (n *Notes) GetMaxId() (uint) {
     return maxId
 }
 
+// Deletes the note. Sub notes of the deleted note are moved under its parent.
 func (n *Notes) DeleteNote(id uint) (error) {
     found := false
+    var parent uint
     for i := 0; i < len(n.notes); i++ {
         note := &n.notes[i]
         if note.Id == id {
+            parent = note.Parent
+            for _, a := range note.Attachments {
+                n.removedFiles = append(n.removedFiles, a.FileId)
+            }
             n.notes = append(n.notes[:i], n.notes[i+1:]...)
             i--
             found = true
@@ -126,14 +202,56 @@ func (n *Notes) DeleteNote(id uint) (error) {
     if !found {
         return errors.New("Could not find note with given id")
     }
+
+    for i, _ := range n.notes {
+        n.RemoveDependency(&n.notes[i], id)
+        if n.notes[i].Parent == id {
+            n.notes[i].Parent = parent
+        }
+    }
     return nil
 }
 
+// Returns name of the current user. Configured user name is preferred over
+// the email address of the authenticated Drive user.
+func (n *Notes) GetCurrentUser() (string) {
+    if len(n.config.UserName) > 0 {
+        return n.config.UserName
+    }
+
+    if n.userLookedUp || n.gdrive == nil {
+        return n.user
+    }
+
+    n.userLookedUp = true
+    about, err := n.gdrive.About.Get().Fields("user(emailAddress)").Do()
+    if err != nil || about.User == nil {
+        return ""
+    }
+    n.user = about.User.EmailAddress
+    return n.user
+}
+
+// Resolves "me" to the current user
+func (n *Notes) ResolveUser(who string) (string, error) {
+    whoStr := strings.Trim(who, " ")
+    if whoStr != "me" {
+        return whoStr, nil
+    }
+
+    user := n.GetCurrentUser()
+    if len(user) == 0 {
+        return "", ErrUnknownUser
+    }
+    return user, nil
+}
+
 func (n *Notes) GetTags() (map[string]int) {
     ret := map[string]int{}
     for i := 0; i < len(n.notes); i++ {
         note := &n.notes[i]
         for _, tag := range note.Tags {
+            tag = n.config.CanonicalTag(tag)
             _, ok := ret[tag]
             if ok {
                 ret[tag] = ret[tag] + 1
@@ -145,15 +263,14 @@ func (n *Notes) GetTags() (map[string]int) {
     return ret
 }
 
+// Returns all tags including the parents of hierarchical tags
 func (n *Notes) GetTagKeys() ([]string) {
-    tags := n.GetTags()
+    tags := n.GetTagTree()
     keys := make([]string, 0, len(tags))
     for k := range tags {
         keys = append(keys, k)
     }
-    sort.Slice(keys, func(i, j int) bool {
-        return keys[i] > keys[j]
-    })
+    SortTags(keys, true)
     return keys
 }
 
@@ -192,13 +309,21 @@ func (n *Notes) categorizeByDue(notes []*Note) ([][]*Note, []string) {
     tomorrow := today.AddDate(0, 0, 1)
 
     for _, note := range notes {
+        // Notes starting today are shown together unless they are due
+        // already
+        startsToday := !note.Scheduled.IsZero() && RoundTimeToDay(note.Scheduled).Equal(today)
         key := "No due"
+        if startsToday {
+            key = "Starting today"
+        }
         if !note.Due.IsZero() {
             rounded := RoundTimeToDay(note.Due)
             if rounded.Before(today) {
                 key = "Past due"
             } else if rounded.Equal(today) {
                 key = "Today"
+            } else if startsToday {
+                key = "Starting today"
             } else if rounded.Equal(tomorrow) {
                 key = "Tomorrow"
             } else {
@@ -224,6 +349,44 @@ func (n *Notes) categorizeByDue(notes []*Note) ([][]*Note, []string) {
 }
 
 
+func (n *Notes) categorizeByStatus(notes []*Note) ([][]*Note, []string) {
+    var ret [][]*Note
+    var keys []string
+
+    for _, note := range notes {
+        key := n.GetStatus(note)
+        keyIdx := -1
+        for i, k := range keys {
+            if key == k {
+                keyIdx = i
+                break
+            }
+        }
+
+        if keyIdx == -1 {
+            keys = append(keys, key)
+            keyIdx = len(keys) - 1
+            ret = append(ret, []*Note{})
+        }
+        ret[keyIdx] = append(ret[keyIdx], note)
+    }
+
+    // Statuses are shown in the workflow order
+    order := n.GetCategoryKeys("status", keys)
+    var sortedRet [][]*Note
+    var sortedKeys []string
+    for _, key := range order {
+        for i, k := range keys {
+            if k == key {
+                sortedKeys = append(sortedKeys, k)
+                sortedRet = append(sortedRet, ret[i])
+                break
+            }
+        }
+    }
+    return sortedRet, sortedKeys
+}
+
 func (n *Notes) CategorizeNotes(category string, notes []*Note) ([][]*Note, []string) {
     var ret[][]*Note
     var keys []string
@@ -232,6 +395,8 @@ func (n *Notes) CategorizeNotes(category string, notes []*Note) ([][]*Note, []st
             return n.categorizeByPriority(notes)
         case "due":
             return n.categorizeByDue(notes)
+        case "status":
+            return n.categorizeByStatus(notes)
     }
 
     keys = append(keys, "")
@@ -239,6 +404,95 @@ func (n *Notes) CategorizeNotes(category string, notes []*Note) ([][]*Note, []st
     return ret, keys
 }
 
+// Returns all possible keys of the category in the order they should be
+// shown. Keys of the categorized notes are given as some categories
+// depend on the notes.
+func (n *Notes) GetCategoryKeys(category string, keys []string) ([]string) {
+    var ret []string
+    switch(category) {
+        case "prio":
+            for i := 5; i >= 0; i-- {
+                ret = append(ret, "Priority " + strconv.Itoa(i))
+            }
+        case "status":
+            ret = append(ret, n.config.Statuses...)
+            for _, key := range keys {
+                if !containsString(ret, key) {
+                    ret = append(ret, key)
+                }
+            }
+        case "due":
+            if containsString(keys, "Past due") {
+                ret = append(ret, "Past due")
+            }
+            ret = append(ret, "Today", "Starting today", "Tomorrow")
+
+            var dates []time.Time
+            for _, key := range keys {
+                date, err := time.Parse(n.config.DueFormat, key)
+                if err == nil {
+                    dates = append(dates, date)
+                }
+            }
+            sort.Slice(dates, func(i, j int) bool {
+                return dates[i].Before(dates[j])
+            })
+            for _, date := range dates {
+                ret = append(ret, date.Format(n.config.DueFormat))
+            }
+            ret = append(ret, "No due")
+        default:
+            ret = append(ret, keys...)
+    }
+    return ret
+}
+
+// Modifies the note so that it belongs to the given key of the category
+func (n *Notes) SetCategory(note *Note, category string, key string) (error) {
+    switch(category) {
+        case "prio":
+            prio, err := strconv.ParseUint(strings.TrimPrefix(key, "Priority "), 10, 32)
+            if err != nil {
+                return err
+            }
+            note.Priority = uint(prio)
+            return nil
+        case "status":
+            return n.SetStatus(note, key)
+        case "due":
+            now := time.Now()
+            today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+            switch(key) {
+                case "Past due":
+                    return errors.New("Notes can't be moved to past due")
+                case "Today":
+                    note.Due = today
+                case "Starting today":
+                    note.Scheduled = today
+                case "Tomorrow":
+                    note.Due = today.AddDate(0, 0, 1)
+                case "No due":
+                    note.Due = time.Time{}
+                default:
+                    due, err := time.Parse(n.config.DueFormat, key)
+                    if err != nil {
+                        return err
+                    }
+                    note.Due = due
+            }
+
+            // Notes starting today are shown as such unless due today so
+            // the start date is cleared when moving them elsewhere. They
+            // are not deferred anymore so clearing it does not hide them.
+            startsToday := !note.Scheduled.IsZero() && RoundTimeToDay(note.Scheduled).Equal(today)
+            if startsToday && key != "Today" && key != "Starting today" {
+                note.Scheduled = time.Time{}
+            }
+            return nil
+    }
+    return errors.New("Invalid category " + category)
+}
+
 func (n *Notes) GetNotes() []*Note {
     var ret[]*Note
     for i, _ := range n.notes {
@@ -258,6 +512,11 @@ func (n *Notes) SearchNotes(str string, notes []*Note) []*Note {
 }
 
 func (n *Notes) ClearNotes() {
+    for _, note := range n.notes {
+        for _, a := range note.Attachments {
+            n.removedFiles = append(n.removedFiles, a.FileId)
+        }
+    }
     n.notes = n.notes[:0]
 }
 
@@ -272,10 +531,46 @@ func (n *Notes) FilterDoneNotes(notes[] *Note) []*Note {
     return ret
 }
 
+// Returns only the done notes
+func (n *Notes) OnlyDoneNotes(notes []*Note) []*Note {
+    var ret []*Note
+    for _, note := range notes {
+        if note.Done {
+            ret = append(ret, note)
+        }
+    }
+    return ret
+}
+
+func (n *Notes) FilterDeferredNotes(notes []*Note) []*Note {
+    var ret []*Note
+    for _, note := range notes {
+        if note.IsDeferred() {
+            continue
+        }
+        ret = append(ret, note)
+    }
+    return ret
+}
+
 func (n *Notes) FilterNotesByTag(tag string, notes []*Note) []*Note {
     var ret []*Note
     for _, note := range notes {
-        if note.HasTag(tag) {
+        if n.NoteHasTag(note, tag) {
+            ret = append(ret, note)
+        }
+    }
+    return ret
+}
+
+func (n *Notes) FilterNotesByAssignee(who string, notes []*Note) []*Note {
+    var ret []*Note
+    who, err := n.ResolveUser(who)
+    if err != nil {
+        return ret
+    }
+    for _, note := range notes {
+        if note.IsAssignedTo(who) {
             ret = append(ret, note)
         }
     }
@@ -319,6 +614,18 @@ func (n *Notes) OrderNotes(columns []string, notes[]*Note) {
                     }
                     ret = notes[i].Due.Unix() < notes[j].Due.Unix()
                     break
+                case "scheduled":
+                    if notes[i].Scheduled.IsZero() {
+                        return false
+                    }
+                    if notes[j].Scheduled.IsZero() {
+                        return true
+                    }
+                    ret = notes[i].Scheduled.Unix() < notes[j].Scheduled.Unix()
+                    break
+                case "estimate":
+                    ret = notes[i].Estimate.Time < notes[j].Estimate.Time || (notes[i].Estimate.Time == notes[j].Estimate.Time && notes[i].Estimate.Points < notes[j].Estimate.Points)
+                    break
                 case "created":
                     ret = notes[i].Created.Unix() < notes[j].Created.Unix()
                     break
@@ -328,6 +635,12 @@ func (n *Notes) OrderNotes(columns []string, notes[]*Note) {
                 case "id":
                     ret = notes[i].Id < notes[j].Id
                     break
+                case "assignee":
+                    ret = strings.ToLower(notes[i].Assignee) < strings.ToLower(notes[j].Assignee)
+                    break
+                case "status":
+                    ret = n.getStatusIndex(notes[i]) < n.getStatusIndex(notes[j])
+                    break
             }
 
             if asc {
@@ -344,16 +657,32 @@ func (n *Notes) OrderNotes(columns []string, notes[]*Note) {
 }
 
 func (n *Notes) createNotesFile() (file *drive.File, err error) {
-    new_file := &drive.File{Name: "notes.json", Parents: []string{"appDataFolder"}}
-    ret, err := n.gdrive.Files.Create(new_file).Do()
+    new_file := &drive.File{Name: "notes.json", Parents: []string{n.getParentFolder()}}
+    create := n.gdrive.Files.Create(new_file).Fields(notesFileFields)
+    if n.config.IsShared() {
+        create.SupportsAllDrives(true)
+    }
+    ret, err := create.Do()
     if err != nil {
         return nil, err
     }
     return ret, nil
 }
 
+// Returns the Drive folder where notes file is stored
+func (n *Notes) getParentFolder() (string) {
+    if n.config.IsShared() {
+        return n.config.SharedFolder
+    }
+    return "appDataFolder"
+}
+
 func (n *Notes) getClient(config *oauth2.Config) *http.Client {
+    // Shared notebooks need broader scope so keep their token separately
     tokFile := n.app_folder + "/token.json"
+    if n.config.IsShared() {
+        tokFile = n.app_folder + "/token_shared.json"
+    }
     tok, err := n.tokenFromFile(tokFile)
     if err != nil {
         tok = n.getTokenFromWeb(config)
@@ -401,52 +730,21 @@ func (n *Notes) saveToken(path string, token *oauth2.Token) {
     json.NewEncoder(f).Encode(token)
 }
 
-func (n *Notes) saveNotes() (err error) {
-    jsonStr, err := json.Marshal(n.notes)
-    if err != nil {
-        return err
-    }
-
-    f, err := os.Create(os.TempDir() + "/" + n.file.Name)
-    if err != nil {
-        return err
-    }
-
-    defer f.Close()
-    _, err = f.Write(jsonStr)
-
-    if err != nil {
-        return err
-    }
-
-    f.Sync()
-    return nil
-}
-
-func (n *Notes) syncNotesFile() (err error) {
-    err = n.saveNotes()
-    if err != nil {
-        return err
-    }
-
-    reader, err := os.Open(os.TempDir() + "/" + n.file.Name)
-    if err != nil {
-        return err
-    }
-
-    update := n.gdrive.Files.Update(n.file.Id, &drive.File{})
-    _, err = update.Media(reader).Do()
-    if err != nil {
-        return err
-    }
-
-    return nil
-}
-
 func (n *Notes) getNotesFile() (file *drive.File, err error) {
     request := n.gdrive.Files.List().PageSize(10)
-    request.Spaces("appDataFolder")
-    request.Fields("nextPageToken, files(id, name, md5Checksum)")
+    if n.config.IsShared() {
+        request.Spaces("drive")
+        request.Q("'" + n.config.SharedFolder + "' in parents and name = 'notes.json' and trashed = false")
+        request.Corpora("allDrives")
+        request.SupportsAllDrives(true)
+        request.IncludeItemsFromAllDrives(true)
+    } else {
+        // Attachments are stored in the same folder so only look for the
+        // notes file
+        request.Spaces("appDataFolder")
+        request.Q("name = 'notes.json' and trashed = false")
+    }
+    request.Fields("nextPageToken, files(" + notesFileFields + ")")
     r, err := request.Do()
     if err != nil {
         log.Fatalf("Unable to retrieve files: %v", err)
@@ -462,67 +760,46 @@ func (n *Notes) getNotesFile() (file *drive.File, err error) {
     return nil, errors.New("Could not find notes file")
 }
 
-func (n *Notes) reloadFromDrive() (err error) {
-    if n.file.Md5Checksum == n.config.Md5Checksum {
-        parse_err := n.parseNotes()
-        if parse_err != nil {
-            return nil
-        }
-    }
+// Loads the notes again if they have been modified in Drive. Unsaved
+// changes are lost. Returns true if the notes were reloaded.
+func (n *Notes) Reload() (bool, error) {
+    n.saveMutex.Lock()
+    defer n.saveMutex.Unlock()
 
-    export := n.gdrive.Files.Get(n.file.Id)
-
-    res, experr := export.Download()
-    if experr != nil {
-        return experr
-    }
-
-    f, err := os.Create(os.TempDir() + "/" + n.file.Name)
+    remote, err := n.getRemoteFile()
     if err != nil {
-        return err
+        return false, err
     }
 
-    defer f.Close()
-    body, readerr := ioutil.ReadAll(res.Body)
-    if readerr != nil {
-        return readerr
+    if remote.HeadRevisionId == n.file.HeadRevisionId && remote.Md5Checksum == n.file.Md5Checksum {
+        return false, nil
     }
-
-    _, err = f.Write(body)
-
-    res.Body.Close()
-    if err != nil {
-        return err
-    }
-
-    f.Sync()
-    n.config.Md5Checksum = n.file.Md5Checksum
-    n.config.Save()
-
-    return n.parseNotes()
+    return true, n.reloadFromDrive(remote)
 }
 
-func (n *Notes) parseNotes() (err error) {
-    dat, err := ioutil.ReadFile(os.TempDir() + "/" + n.file.Name)
-    if err != nil {
-        return err
-    }
-
-    if len(dat) == 0 {
-        return nil
-    }
-
-    notesJSON := make([]Note, 0)
-    err = json.Unmarshal(dat, &notesJSON)
-    if err != nil {
-        return err
+// Loads the notes from the given file. The cached notes file is used if it
+// has not been modified in Drive.
+func (n *Notes) reloadFromDrive(file *drive.File) (error) {
+    notes, ok := n.readCache(file)
+    if !ok {
+        var err error
+        notes, err = n.downloadNotes(file)
+        if err != nil {
+            return err
+        }
+        n.config.Md5Checksum = file.Md5Checksum
+        n.config.Save()
     }
 
-    n.notes = notesJSON
+    n.notes = notes
     if len(n.notes) > 0 {
         n.max_id = n.notes[len(n.notes)-1].Id
     }
+    n.takeSnapshot()
 
+    n.setRemote(file, cloneNotes(notes))
+    n.synced = notesById(cloneNotes(notes))
+    n.syncedVersion = n.version
     return nil
 }
 
@@ -541,7 +818,14 @@ func (n *Notes) setUpDrive() (error) {
             ]
         }
     }`)
-    config, err := google.ConfigFromJSON(b, drive.DriveAppdataScope)
+    scope := drive.DriveAppdataScope
+    if n.config.IsShared() {
+        // Files in regular folders and shared drives are not accessible
+        // with the application data scope
+        scope = drive.DriveScope
+    }
+
+    config, err := google.ConfigFromJSON(b, scope)
     if err != nil {
         return err
     }
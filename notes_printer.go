@@ -1,7 +1,9 @@
 package main
 
 import(
+    "encoding/csv"
     "fmt"
+    "os"
     "strings"
     "strconv"
     "time"
@@ -13,11 +15,16 @@ type NotesPrinter struct {
     UseColor bool
     PrintHeader bool
     SkipDone bool
+    SkipBlocked bool
+    SkipDeferred bool
     ShowDone bool
     ShowCreated bool
     ShowUpdated bool
     ShowPriority bool
     ShowDue bool
+    ShowAssignee bool
+    ShowStatus bool
+    ShowEstimate bool
     MaxTitleLength int
     TimeFormat string
     DueFormat string
@@ -25,13 +32,21 @@ type NotesPrinter struct {
     SearchStr string
     PrioFilter uint
     TagFilter string
+    AssigneeFilter string
+    Query *Query
     PrintDetails bool
+    Tree bool
+    depths map[uint]int
+    progress map[uint]string
     idSize int
     doneSize int
     titleSize int
     prioSize int
     timeSize int
     dueSize int
+    assigneeSize int
+    statusSize int
+    estimateSize int
 }
 
 func NewNotesPrinter(config *Configuration) (NotesPrinter) {
@@ -45,19 +60,30 @@ func NewNotesPrinter(config *Configuration) (NotesPrinter) {
     inst.ShowUpdated = false
     inst.ShowPriority = config.UsePriority
     inst.ShowDue = config.UseDue
+    inst.ShowAssignee = config.IsShared()
+    inst.ShowStatus = true
     inst.TimeFormat = config.TimeFormat
     inst.DueFormat = config.DueFormat
     inst.SortColumns = append(inst.SortColumns, "id")
     inst.SearchStr = ""
     inst.TagFilter = ""
+    inst.AssigneeFilter = ""
     inst.PrioFilter = 0
     inst.PrintDetails = false
 
     inst.idSize = 6
     inst.doneSize = 6
     inst.titleSize = 30
-    inst.timeSize = 12
+    inst.timeSize = len(time.Now().Format(inst.TimeFormat)) + 2
     inst.prioSize = 6
+    inst.assigneeSize = 8
+    inst.statusSize = 8
+    inst.estimateSize = 7
+    for _, status := range config.Statuses {
+        if len(status) + 2 > inst.statusSize {
+            inst.statusSize = len(status) + 2
+        }
+    }
 
     return inst
 }
@@ -66,7 +92,21 @@ func (p *NotesPrinter) calculateColumnWidths(n *Notes) {
     now := time.Now()
     p.timeSize = len(now.Format(p.TimeFormat)) + 2
     p.dueSize = len(now.Format(p.DueFormat)) + 2
-    p.idSize = len(string(n.GetMaxId())) + 3
+    p.idSize = len(strconv.Itoa(int(n.GetMaxId()))) + 3
+
+    p.assigneeSize = 8
+    for _, note := range n.GetNotes() {
+        if len(note.Assignee) + 2 > p.assigneeSize {
+            p.assigneeSize = len(note.Assignee) + 2
+        }
+        // Notes can have statuses set by others that are not configured
+        if len(note.Status) + 2 > p.statusSize {
+            p.statusSize = len(note.Status) + 2
+        }
+    }
+    if p.assigneeSize > 24 {
+        p.assigneeSize = 24
+    }
 
     w := GetScreenWidth() - 2
     w -= p.idSize
@@ -79,6 +119,15 @@ func (p *NotesPrinter) calculateColumnWidths(n *Notes) {
     if p.ShowDue {
         w -= p.dueSize
     }
+    if p.ShowAssignee {
+        w -= p.assigneeSize
+    }
+    if p.ShowStatus {
+        w -= p.statusSize
+    }
+    if p.ShowEstimate {
+        w -= p.estimateSize
+    }
     if p.ShowCreated {
         w -= p.timeSize
     }
@@ -96,6 +145,14 @@ func (p *NotesPrinter) Print(n *Notes) {
         notes = n.FilterDoneNotes(notes)
     }
 
+    if p.SkipBlocked {
+        notes = n.FilterBlockedNotes(notes)
+    }
+
+    if p.SkipDeferred {
+        notes = n.FilterDeferredNotes(notes)
+    }
+
     if len(p.SearchStr) > 0 {
         notes = n.SearchNotes(p.SearchStr, notes)
     }
@@ -104,7 +161,31 @@ func (p *NotesPrinter) Print(n *Notes) {
         notes = n.FilterNotesByTag(p.TagFilter, notes)
     }
 
+    if len(p.AssigneeFilter) > 0 {
+        notes = n.FilterNotesByAssignee(p.AssigneeFilter, notes)
+    }
+
+    if p.Query != nil {
+        notes = n.QueryNotes(p.Query, notes)
+    }
+
+    p.PrintNotes(n, notes)
+}
+
+// Prints the given notes without filtering them
+func (p *NotesPrinter) PrintNotes(n *Notes, notes []*Note) {
     n.OrderNotes(p.SortColumns, notes)
+    if p.Tree {
+        notes, p.depths = n.OrderAsTree(notes)
+    }
+
+    p.progress = map[uint]string{}
+    for _, note := range notes {
+        progress := n.GetProgressString(note)
+        if len(progress) > 0 {
+            p.progress[note.Id] = progress
+        }
+    }
 
     for _, col := range p.SortColumns {
         switch(col) {
@@ -120,6 +201,22 @@ func (p *NotesPrinter) Print(n *Notes) {
             case "prio":
                 p.ShowPriority = true
                 break
+            case "assignee":
+                p.ShowAssignee = true
+                break
+            case "status":
+                p.ShowStatus = true
+                break
+            case "estimate":
+                p.ShowEstimate = true
+                break
+        }
+    }
+
+    for _, note := range notes {
+        if !note.Estimate.IsZero() {
+            p.ShowEstimate = true
+            break
         }
     }
 
@@ -132,26 +229,29 @@ func (p *NotesPrinter) Print(n *Notes) {
         p.printHeader()
     }
 
-    notesPrinted := false
+    var printed []*Note
     for _, note := range notes {
         if p.ShowPriority && note.Priority < p.PrioFilter {
             continue
         }
 
         if p.PrintDetails {
-           p.PrintFullNote(note)
+           p.PrintFullNote(n, note)
         } else {
-            p.PrintNote(note)
+            p.PrintNote(n, note)
             fmt.Print("\n")
         }
-        notesPrinted = true
+        printed = append(printed, note)
     }
 
-    if !notesPrinted {
+    if len(printed) == 0 {
         fmt.Println("No notes")
     }
 
     PrintVerticalLine()
+    if len(printed) > 0 && !p.PrintDetails {
+        fmt.Println(" " + GetEstimateSummary(printed))
+    }
 }
 
 func (p *NotesPrinter) printHeader() {
@@ -176,6 +276,15 @@ func (p *NotesPrinter) printHeader() {
     if p.ShowDue {
         c.Printf("%-" + strconv.Itoa(p.dueSize) + "v", "DUE")
     }
+    if p.ShowEstimate {
+        c.Printf("%-" + strconv.Itoa(p.estimateSize) + "v", "EST")
+    }
+    if p.ShowStatus {
+        c.Printf("%-" + strconv.Itoa(p.statusSize) + "v", "STATUS")
+    }
+    if p.ShowAssignee {
+        c.Printf("%-" + strconv.Itoa(p.assigneeSize) + "v", "OWNER")
+    }
     if p.ShowCreated {
         c.Printf("%-" + strconv.Itoa(p.timeSize) + "v", "CREATED")
     }
@@ -187,7 +296,7 @@ func (p *NotesPrinter) printHeader() {
     PrintVerticalLine()
 }
 
-func (p *NotesPrinter) PrintNote(n *Note) {
+func (p *NotesPrinter) PrintNote(notes *Notes, n *Note) {
     fmt.Printf(" %-" + strconv.Itoa(p.idSize) + "v", n.Id)
 
     if p.ShowDone {
@@ -198,8 +307,13 @@ func (p *NotesPrinter) PrintNote(n *Note) {
         }
     }
 
-    parts := strings.Split(n.Content, "\n")
-    preview := parts[0]
+    preview := n.GetTitle()
+    if p.Tree {
+        preview = strings.Repeat("  ", p.depths[n.Id]) + preview
+    }
+    if progress, ok := p.progress[n.Id]; ok {
+        preview += " " + progress
+    }
     if len(preview) > (p.titleSize - 3) {
         preview = preview[0:(p.titleSize-3)] + "..."
     }
@@ -219,6 +333,22 @@ func (p *NotesPrinter) PrintNote(n *Note) {
         fmt.Printf("%-" + strconv.Itoa(p.dueSize) + "v", due)
     }
 
+    if p.ShowEstimate {
+        fmt.Printf("%-" + strconv.Itoa(p.estimateSize) + "v", n.Estimate.String())
+    }
+
+    if p.ShowStatus {
+        fmt.Printf("%-" + strconv.Itoa(p.statusSize) + "v", notes.GetStatus(n))
+    }
+
+    if p.ShowAssignee {
+        assignee := n.Assignee
+        if len(assignee) > (p.assigneeSize - 2) {
+            assignee = assignee[0:(p.assigneeSize - 5)] + "..."
+        }
+        fmt.Printf("%-" + strconv.Itoa(p.assigneeSize) + "v", assignee)
+    }
+
     if p.ShowCreated {
         created := ""
         if !n.Created.IsZero() {
@@ -236,7 +366,7 @@ func (p *NotesPrinter) PrintNote(n *Note) {
     }
 }
 
-func (p *NotesPrinter) PrintFullNote(n *Note) {
+func (p *NotesPrinter) PrintFullNote(notes *Notes, n *Note) {
     c := color.New(color.FgHiGreen).Add(color.Underline)
     PrintVerticalLine()
     c.Printf("NOTE %v\n\n", n.Id)
@@ -250,15 +380,81 @@ func (p *NotesPrinter) PrintFullNote(n *Note) {
         fmt.Println("Due: " + n.Due.Format(p.DueFormat))
     }
 
+    if !n.Scheduled.IsZero() {
+        fmt.Println("Starts: " + n.Scheduled.Format(p.DueFormat))
+    }
+
+    if !n.Estimate.IsZero() {
+        fmt.Println("Estimate: " + n.Estimate.String())
+    }
+
     if len(n.Tags) > 0 {
         fmt.Println("Tags: " + strings.Join(n.Tags, ", "))
     }
 
+    fmt.Println("Status: " + notes.GetStatus(n))
+
+    if n.Done && !n.CompletedAt.IsZero() {
+        fmt.Println("Completed: " + n.CompletedAt.Format(p.TimeFormat))
+    }
+
+    if len(n.Assignee) > 0 {
+        fmt.Println("Assignee: " + n.Assignee)
+    }
+
+    if len(n.Watchers) > 0 {
+        fmt.Println("Watchers: " + strings.Join(n.Watchers, ", "))
+    }
+
+    if len(n.TimeEntries) > 0 {
+        fmt.Println("Time tracked: " + FormatDuration(n.GetTrackedTime().Round(time.Minute)))
+    }
+
+    reminder := notes.GetNextReminder(n)
+    if reminder != nil {
+        fmt.Println("Next reminder: " + reminder.GetTime(n).Format(p.TimeFormat))
+    }
+
     noteUrls := len(n.GetUrls())
     if noteUrls > 0 {
         fmt.Println("URLs: " + strconv.Itoa(noteUrls))
     }
 
+    if len(n.Comments) > 0 {
+        fmt.Println("Comments: " + strconv.Itoa(len(n.Comments)))
+    }
+
+    if len(n.Attachments) > 0 {
+        fmt.Println("Attachments: " + strings.Join(n.GetAttachmentNames(), ", "))
+    }
+
+    if n.Parent != 0 {
+        parent := notes.FindNote(n.Parent)
+        if parent != nil {
+            fmt.Println("Parent: " + strings.Join(GetNoteReferences([]*Note{parent}), ""))
+        }
+    }
+
+    progress := notes.GetProgressString(n)
+    if len(progress) > 0 {
+        fmt.Println("Sub notes done: " + progress)
+    }
+
+    blockers := notes.GetBlockers(n)
+    if len(blockers) > 0 {
+        fmt.Println("Blocked by: " + strings.Join(GetNoteReferences(blockers), ", "))
+    }
+
+    links := notes.GetLinkedNotes(n)
+    if len(links) > 0 {
+        fmt.Println("Links: " + strings.Join(GetNoteReferences(links), ", "))
+    }
+
+    backlinks := notes.GetBacklinks(n)
+    if len(backlinks) > 0 {
+        fmt.Println("Linked from: " + strings.Join(GetNoteReferences(backlinks), ", "))
+    }
+
     fmt.Println("Created: " + n.Created.Format(p.TimeFormat))
     fmt.Println("Updated: " + n.Updated.Format(p.TimeFormat))
     fmt.Print("\n")
@@ -266,3 +462,503 @@ func (p *NotesPrinter) PrintFullNote(n *Note) {
     fmt.Print("\n")
     PrintVerticalLine()
 }
+
+func (p *NotesPrinter) PrintActivity(n *Note) {
+    c := color.New(color.FgHiGreen).Add(color.Underline)
+    PrintVerticalLine()
+    c.Printf("ACTIVITY OF NOTE %v\n\n", n.Id)
+
+    if len(n.Activity) == 0 {
+        fmt.Println("No activity")
+    }
+
+    for _, activity := range n.Activity {
+        p.printActivityLine(&activity, "")
+    }
+    PrintVerticalLine()
+}
+
+func (p *NotesPrinter) PrintReminders(n *Note) {
+    c := color.New(color.FgHiGreen).Add(color.Underline)
+    PrintVerticalLine()
+    c.Printf("REMINDERS OF NOTE %v\n\n", n.Id)
+
+    if len(n.Reminders) == 0 {
+        fmt.Println("No reminders")
+    }
+
+    for i, r := range n.Reminders {
+        line := strconv.Itoa(i + 1) + ". " + r.Describe(p.TimeFormat)
+        t := r.GetTime(n)
+        if r.Relative && !t.IsZero() {
+            line += " (" + t.Format(p.TimeFormat) + ")"
+        }
+        if r.IsFired(n) {
+            line += " [delivered]"
+        }
+        if len(r.Owner) > 0 {
+            line += " for " + r.Owner
+        }
+        fmt.Println(line)
+    }
+    PrintVerticalLine()
+}
+
+// Prints hierarchical tags as a tree with the count of notes under each tag
+func (p *NotesPrinter) PrintTagTree(tags map[string]int) {
+    keys := make([]string, 0, len(tags))
+    for k := range tags {
+        keys = append(keys, k)
+    }
+    SortTags(keys, false)
+
+    for _, tag := range keys {
+        indent := strings.Repeat("  ", strings.Count(tag, TAG_SEPARATOR))
+        fmt.Printf("%v%v (%v notes)\n", indent, GetTagName(tag), tags[tag])
+    }
+}
+
+func (p *NotesPrinter) PrintComments(n *Note) {
+    c := color.New(color.FgHiGreen).Add(color.Underline)
+    author := color.New(color.Bold)
+    if !p.UseColor {
+        author.DisableColor()
+    }
+    PrintVerticalLine()
+    c.Printf("COMMENTS OF NOTE %v\n", n.Id)
+
+    if len(n.Comments) == 0 {
+        fmt.Println("\nNo comments")
+    }
+
+    for _, comment := range n.Comments {
+        fmt.Print("\n")
+        author.Print(comment.Author)
+        fmt.Println(" " + comment.Created.Format(p.TimeFormat))
+        fmt.Println(comment.Text)
+    }
+    PrintVerticalLine()
+}
+
+func (p *NotesPrinter) PrintAttachments(n *Note) {
+    c := color.New(color.FgHiGreen).Add(color.Underline)
+    PrintVerticalLine()
+    c.Printf("ATTACHMENTS OF NOTE %v\n\n", n.Id)
+
+    if len(n.Attachments) == 0 {
+        fmt.Println("No attachments")
+    }
+
+    for i, a := range n.Attachments {
+        fmt.Printf("%3v. %v (%v)\n", i + 1, a.Name, FormatSize(a.Size))
+    }
+    PrintVerticalLine()
+}
+
+func (p *NotesPrinter) PrintActivitySummary(activities []NoteActivity, since time.Time) {
+    c := color.New(color.FgHiGreen).Add(color.Underline)
+    PrintVerticalLine()
+    c.Printf("ACTIVITY SINCE %v\n", since.Format(p.TimeFormat))
+
+    notes := map[uint]bool{}
+    day := ""
+    for _, a := range activities {
+        activityDay := a.Activity.Time.Format(p.DueFormat)
+        if activityDay != day {
+            day = activityDay
+            fmt.Print("\n")
+            c.Println(day)
+        }
+        notes[a.Note.Id] = true
+        p.printActivityLine(a.Activity, "#" + strconv.Itoa(int(a.Note.Id)) + " " + a.Note.GetTitle() + ": ")
+    }
+
+    fmt.Print("\n")
+    fmt.Printf("%v changes in %v notes\n", len(activities), len(notes))
+    PrintVerticalLine()
+}
+
+func (p *NotesPrinter) printActivityLine(a *Activity, prefix string) {
+    c := color.New(color.FgHiBlack)
+    if !p.UseColor {
+        c.DisableColor()
+    }
+    c.Printf("%-" + strconv.Itoa(p.timeSize) + "v", a.Time.Format(p.TimeFormat))
+    fmt.Print(prefix + a.Action)
+    if len(a.Author) > 0 {
+        c.Printf(" (%v)", a.Author)
+    }
+    fmt.Print("\n")
+}
+
+// Prints not done notes due during the given number of days starting today
+// and notes that are already past due
+func (p *NotesPrinter) PrintAgenda(n *Notes, days int) {
+    header := color.New(color.FgHiGreen).Add(color.Underline)
+    if !p.UseColor {
+        header.DisableColor()
+    }
+
+    p.calculateColumnWidths(n)
+    today := GetToday()
+    PrintVerticalLine()
+
+    overdue := n.GetOverdueNotes(today)
+    if len(overdue) > 0 {
+        n.OrderNotes([]string{"due"}, overdue)
+        header.Println("Past due")
+        for _, note := range overdue {
+            p.printAgendaLine(note, true)
+        }
+        fmt.Println("")
+    }
+
+    open := n.FilterDoneNotes(n.GetNotes())
+    for i := 0; i < days; i++ {
+        day := today.AddDate(0, 0, i)
+        title := day.Format("Mon") + " " + day.Format(p.DueFormat)
+        switch(i) {
+            case 0:
+                title += " (today)"
+                break
+            case 1:
+                title += " (tomorrow)"
+                break
+        }
+        header.Println(title)
+
+        due := n.FilterNotesByDueDay(day, open)
+        n.OrderNotes([]string{"-prio"}, due)
+        if len(due) == 0 {
+            fmt.Println("  -")
+        }
+        for _, note := range due {
+            p.printAgendaLine(note, false)
+        }
+    }
+    PrintVerticalLine()
+}
+
+func (p *NotesPrinter) printAgendaLine(n *Note, showDue bool) {
+    fmt.Printf("  %-" + strconv.Itoa(p.idSize) + "v", n.Id)
+    if p.ShowPriority {
+        c := GetPriorityColor(n)
+        if !p.UseColor {
+            c.DisableColor()
+        }
+        c.Printf("%-3v", n.Priority)
+    }
+    if showDue {
+        fmt.Printf("%-" + strconv.Itoa(p.dueSize) + "v", n.Due.Format(p.DueFormat))
+    }
+    fmt.Println(n.GetTitle())
+}
+
+// Prints month grid with the number of due notes on each day
+func (p *NotesPrinter) PrintCalendar(n *Notes, month time.Time) {
+    c := color.New(color.FgHiGreen).Add(color.Underline)
+    if !p.UseColor {
+        c.DisableColor()
+    }
+
+    PrintVerticalLine()
+    c.Printf("%v %v\n\n", month.Month(), month.Year())
+    n.WriteMonth(color.Output, month, time.Time{}, true, p.UseColor)
+    PrintVerticalLine()
+}
+
+// Prints tracked time per group either as a table or as CSV with the time in
+// hours
+func (p *NotesPrinter) PrintTimeReport(keys []string, totals map[string]time.Duration, total time.Duration, by string, asCsv bool) {
+    if asCsv {
+        w := csv.NewWriter(os.Stdout)
+        w.Write([]string{by, "hours"})
+        for _, key := range keys {
+            w.Write([]string{key, strconv.FormatFloat(totals[key].Hours(), 'f', 2, 64)})
+        }
+        w.Flush()
+        return
+    }
+
+    c := color.New(color.Bold).Add(color.FgHiCyan)
+    if !p.UseColor {
+        c.DisableColor()
+    }
+
+    keySize := len(by) + 2
+    for _, key := range keys {
+        if len(key) + 2 > keySize {
+            keySize = len(key) + 2
+        }
+    }
+    if keySize > 50 {
+        keySize = 50
+    }
+    format := " %-" + strconv.Itoa(keySize) + "v%10v%10v\n"
+
+    PrintVerticalLine()
+    c.Printf(format, strings.ToUpper(by), "TIME", "HOURS")
+    PrintVerticalLine()
+    if len(keys) == 0 {
+        fmt.Println("No time tracked")
+    }
+    for _, key := range keys {
+        title := key
+        if len(title) > keySize - 2 {
+            title = title[0:keySize - 5] + "..."
+        }
+        d := totals[key].Round(time.Minute)
+        fmt.Printf(format, title, FormatDuration(d), strconv.FormatFloat(d.Hours(), 'f', 2, 64))
+    }
+    PrintVerticalLine()
+    total = total.Round(time.Minute)
+    c.Printf(format, "TOTAL", FormatDuration(total), strconv.FormatFloat(total.Hours(), 'f', 2, 64))
+}
+
+// Prints time estimates compared against the logged time per tag
+func (p *NotesPrinter) PrintEstimateReport(keys []string, report map[string]*EstimateAccuracy, asCsv bool) {
+    if asCsv {
+        w := csv.NewWriter(os.Stdout)
+        w.Write([]string{"tag", "notes", "estimated_hours", "actual_hours", "ratio"})
+        for _, key := range keys {
+            a := report[key]
+            w.Write([]string{key, strconv.Itoa(a.Notes),
+                strconv.FormatFloat(a.Estimated.Hours(), 'f', 2, 64),
+                strconv.FormatFloat(a.Actual.Hours(), 'f', 2, 64),
+                strconv.FormatFloat(a.GetRatio(), 'f', 2, 64)})
+        }
+        w.Flush()
+        return
+    }
+
+    c := color.New(color.Bold).Add(color.FgHiCyan)
+    under := color.New(color.FgHiRed)
+    if !p.UseColor {
+        c.DisableColor()
+        under.DisableColor()
+    }
+
+    keySize := 5
+    for _, key := range keys {
+        if len(key) + 2 > keySize {
+            keySize = len(key) + 2
+        }
+    }
+    format := " %-" + strconv.Itoa(keySize) + "v%7v%11v%11v%8v\n"
+
+    PrintVerticalLine()
+    c.Printf(format, "TAG", "NOTES", "ESTIMATED", "ACTUAL", "RATIO")
+    PrintVerticalLine()
+    if len(keys) == 0 {
+        fmt.Println("No estimated notes")
+    }
+    for _, key := range keys {
+        a := report[key]
+        line := fmt.Sprintf(format, key, a.Notes, FormatHours(a.Estimated), FormatHours(a.Actual.Round(time.Minute)),
+            strconv.FormatFloat(a.GetRatio(), 'f', 2, 64))
+
+        // Notes taking over 20% more than estimated are highlighted
+        if a.GetRatio() > 1.2 {
+            under.Print(line)
+        } else {
+            fmt.Print(line)
+        }
+    }
+    PrintVerticalLine()
+}
+
+func (p *NotesPrinter) PrintStats(stats Stats) {
+    c := color.New(color.FgHiGreen).Add(color.Underline)
+    bold := color.New(color.Bold)
+    if !p.UseColor {
+        c.DisableColor()
+        bold.DisableColor()
+    }
+
+    PrintVerticalLine()
+    c.Println("STATISTICS")
+    fmt.Println("")
+
+    total := stats.Open + stats.Done
+    donePercent := 0
+    if total > 0 {
+        donePercent = stats.Done * 100 / total
+    }
+    fmt.Printf("Open: %v  Done: %v  (%v%% done)  Overdue: %v\n", stats.Open, stats.Done, donePercent, stats.Overdue)
+    if stats.AverageCompletionHours > 0 {
+        d := time.Duration(stats.AverageCompletionHours * float64(time.Hour))
+        fmt.Printf("Average time to complete: %v\n", FormatDuration(d.Round(time.Hour)))
+    }
+    fmt.Println("")
+
+    var created []int
+    var completed []int
+    createdSum := 0
+    completedSum := 0
+    for _, week := range stats.Weeks {
+        created = append(created, week.Created)
+        completed = append(completed, week.Completed)
+        createdSum += week.Created
+        completedSum += week.Completed
+    }
+
+    if len(stats.Weeks) > 0 {
+        bold.Printf("Per week since %v\n", stats.Weeks[0].Start)
+        fmt.Printf("  Created    %v %v\n", Sparkline(created), createdSum)
+        fmt.Printf("  Completed  %v %v\n", Sparkline(completed), completedSum)
+        fmt.Println("")
+    }
+
+    if stats.Overdue > 0 {
+        bold.Println("Overdue by priority")
+        for prio := 5; prio >= 0; prio-- {
+            count := stats.OverdueByPriority[strconv.Itoa(prio)]
+            if count == 0 {
+                continue
+            }
+            prioColor := GetPriorityColor(&Note{Priority: uint(prio)})
+            if !p.UseColor {
+                prioColor.DisableColor()
+            }
+            fmt.Printf("  %v %v %v\n", prio, prioColor.Sprint(Bar(count, stats.Overdue, 30)), count)
+        }
+        fmt.Println("")
+    }
+
+    if len(stats.TopTags) > 0 {
+        bold.Println("Top tags")
+        tagSize := 0
+        for _, tag := range stats.TopTags {
+            if len(tag.Tag) > tagSize {
+                tagSize = len(tag.Tag)
+            }
+        }
+        for _, tag := range stats.TopTags {
+            fmt.Printf("  %-" + strconv.Itoa(tagSize) + "v %v %v\n", tag.Tag, Bar(tag.Count, stats.TopTags[0].Count, 30), tag.Count)
+        }
+    }
+    PrintVerticalLine()
+}
+
+// Prints remaining open notes per day as a chart with the ideal line
+func (p *NotesPrinter) PrintBurndown(days []BurndownDay, start int, asCsv bool) {
+    if asCsv {
+        w := csv.NewWriter(os.Stdout)
+        w.Write([]string{"date", "remaining", "ideal"})
+        for _, day := range days {
+            remaining := ""
+            if !day.Future {
+                remaining = strconv.Itoa(day.Remaining)
+            }
+            w.Write([]string{day.Day.Format("2006-01-02"), remaining, strconv.FormatFloat(day.Ideal, 'f', 2, 64)})
+        }
+        w.Flush()
+        return
+    }
+
+    if len(days) == 0 {
+        fmt.Println("No days to show")
+        return
+    }
+
+    bar := color.New(color.FgHiCyan)
+    ideal := color.New(color.FgHiYellow)
+    if !p.UseColor {
+        bar.DisableColor()
+        ideal.DisableColor()
+    }
+
+    max := start
+    for _, day := range days {
+        if day.Remaining > max {
+            max = day.Remaining
+        }
+    }
+    if max == 0 {
+        max = 1
+    }
+
+    // Large amounts of notes are scaled to fit the chart height
+    height := max
+    if height > 15 {
+        height = 15
+    }
+    scale := float64(max) / float64(height)
+    labelSize := len(strconv.Itoa(max))
+
+    PrintVerticalLine()
+    for row := height; row > 0; row-- {
+        label := ""
+        if row == height || row == 1 || row == (height + 1) / 2 {
+            label = strconv.Itoa(int(float64(row) * scale + 0.5))
+        }
+        fmt.Printf("%" + strconv.Itoa(labelSize) + "v |", label)
+
+        for _, day := range days {
+            filled := int(float64(day.Remaining) / scale + 0.5)
+            idealRow := int(day.Ideal / scale + 0.5)
+            if !day.Future && filled >= row && idealRow == row {
+                fmt.Print(ideal.Sprint("▓▓") + " ")
+            } else if !day.Future && filled >= row {
+                fmt.Print(bar.Sprint("██") + " ")
+            } else if idealRow == row {
+                fmt.Print(ideal.Sprint("··") + " ")
+            } else {
+                fmt.Print("   ")
+            }
+        }
+        fmt.Println("")
+    }
+
+    fmt.Printf("%" + strconv.Itoa(labelSize) + "v +%v\n", 0, strings.Repeat("---", len(days)))
+    fmt.Printf("%" + strconv.Itoa(labelSize) + "v  ", "")
+    for _, day := range days {
+        fmt.Printf("%2d ", day.Day.Day())
+    }
+    fmt.Println("")
+    PrintVerticalLine()
+
+    last := days[0]
+    for _, day := range days {
+        if !day.Future {
+            last = day
+        }
+    }
+    fmt.Printf("%v - %v: %v notes at start, %v remaining on %v (ideal %v)\n",
+        days[0].Day.Format("2006-01-02"), days[len(days)-1].Day.Format("2006-01-02"),
+        start, last.Remaining, last.Day.Format("2006-01-02"),
+        strconv.FormatFloat(last.Ideal, 'f', 1, 64))
+    fmt.Printf("%v remaining  %v ideal\n", bar.Sprint("██"), ideal.Sprint("··/▓▓"))
+}
+
+// Prints templates with their presets and prompted values
+func (p *NotesPrinter) PrintTemplates(templates []*Template, folder string) {
+    c := color.New(color.Bold).Add(color.FgHiCyan)
+    if !p.UseColor {
+        c.DisableColor()
+    }
+
+    nameSize := 10
+    for _, template := range templates {
+        if len(template.Name) + 2 > nameSize {
+            nameSize = len(template.Name) + 2
+        }
+    }
+    format := " %-" + strconv.Itoa(nameSize) + "v%-20v%-6v%-6v%v\n"
+
+    PrintVerticalLine()
+    c.Printf(format, "NAME", "TAGS", "PRIO", "DUE", "PROMPTS")
+    PrintVerticalLine()
+    if len(templates) == 0 {
+        fmt.Println("No templates")
+    }
+    for _, template := range templates {
+        prio := ""
+        if template.Priority >= 0 {
+            prio = strconv.Itoa(template.Priority)
+        }
+        fmt.Printf(format, template.Name, strings.Join(template.Tags, ", "), prio, template.Due, strings.Join(template.GetPrompts(), ", "))
+    }
+    PrintVerticalLine()
+    fmt.Println(" Templates are stored in " + folder)
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+    "net/http"
+    "net/http/httptest"
+    "testing"
+
+    "google.golang.org/api/drive/v3"
+)
+
+func TestResolveUser(t *testing.T) {
+    tests := []struct {
+        userName string
+        who string
+        expected string
+        err bool
+    }{
+        {"alice", "me", "alice", false},
+        {"alice", " bob ", "bob", false},
+        {"alice", "", "", false},
+        {"", "bob", "bob", false},
+        {"", "me", "", true},
+    }
+
+    for _, test := range tests {
+        n := newTestNotes()
+        n.config.UserName = test.userName
+        who, err := n.ResolveUser(test.who)
+        if test.err {
+            if err == nil {
+                t.Errorf("ResolveUser(%q) with user %q should fail", test.who, test.userName)
+            }
+            continue
+        }
+        if err != nil {
+            t.Errorf("ResolveUser(%q) with user %q failed: %v", test.who, test.userName, err)
+            continue
+        }
+        if who != test.expected {
+            t.Errorf("ResolveUser(%q) with user %q = %q, want %q", test.who, test.userName, who, test.expected)
+        }
+    }
+}
+
+// Notes moved on the due board have to end up in the column they were moved to
+func TestSetCategoryDue(t *testing.T) {
+    today := GetToday()
+    later := today.AddDate(0, 0, 5).Format("02.01.2006")
+
+    tests := []struct {
+        name string
+        note Note
+        column string
+    }{
+        {"starting today to today", Note{Scheduled: today, Due: today.AddDate(0, 0, 3)}, "Today"},
+        {"starting today to tomorrow", Note{Scheduled: today, Due: today.AddDate(0, 0, 3)}, "Tomorrow"},
+        {"starting today to date", Note{Scheduled: today}, later},
+        {"starting today to no due", Note{Scheduled: today, Due: today.AddDate(0, 0, 3)}, "No due"},
+        {"tomorrow to starting today", Note{Due: today.AddDate(0, 0, 1)}, "Starting today"},
+        {"no due to tomorrow", Note{}, "Tomorrow"},
+    }
+
+    for _, test := range tests {
+        test.note.Id = 1
+        n := newTestNotes(test.note)
+        note := n.FindNote(1)
+        err := n.SetCategory(note, "due", test.column)
+        if err != nil {
+            t.Errorf("%v: SetCategory() failed: %v", test.name, err)
+            continue
+        }
+
+        _, keys := n.CategorizeNotes("due", n.GetNotes())
+        if len(keys) != 1 || keys[0] != test.column {
+            t.Errorf("%v: note is in %v, want %v", test.name, keys, test.column)
+        }
+    }
+
+    n := newTestNotes(Note{Id: 1, Due: today.AddDate(0, 0, -1)})
+    if n.SetCategory(n.FindNote(1), "due", "Past due") == nil {
+        t.Errorf("Moving note to past due should fail")
+    }
+}
+
+func TestGetCurrentUserLooksUpOnce(t *testing.T) {
+    requests := 0
+    server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        requests++
+        w.WriteHeader(http.StatusUnauthorized)
+    }))
+    defer server.Close()
+
+    srv, err := drive.New(server.Client())
+    if err != nil {
+        t.Fatal(err)
+    }
+    srv.BasePath = server.URL + "/"
+
+    n := newTestNotes()
+    n.gdrive = srv
+    for i := 0; i < 3; i++ {
+        if user := n.GetCurrentUser(); len(user) > 0 {
+            t.Errorf("GetCurrentUser() = %q, want no user", user)
+        }
+    }
+    if requests != 1 {
+        t.Errorf("Drive was asked for the user %v times, want once", requests)
+    }
+}
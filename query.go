@@ -0,0 +1,427 @@
+package main
+
+import (
+    "errors"
+    "strconv"
+    "strings"
+    "time"
+)
+
+// Maximum number of ids in a single range such as "10-14"
+const MAX_ID_RANGE = 10000
+
+// Query for selecting notes, for example 'tag:sprint12 AND NOT done'.
+//
+// Supported terms are:
+//   tag:<tag>          Note has the tag or tag under it
+//   prio:<n>           Priority is at least n. Also prio=, prio<, prio<=,
+//                      prio> and prio>= can be used
+//   done / open        Note is done or not done
+//   blocked            Note is blocked by other notes
+//   id:<ids>           Note id is in the list, for example id:3,5,10-14
+//   parent:<id>        Note is under the note with given id
+//   assignee:<who>     Note is assigned to the user, "me" for yourself
+//   mine               Note is assigned to you
+//   due:<when>         Due is today, tomorrow, overdue, none, any or date
+//   <text>             Note content or tags contain the text
+//
+// Terms can be combined with AND, OR, NOT and parentheses. Terms next to
+// each other without operator are combined with AND.
+type Query struct {
+    root queryNode
+}
+
+type queryNode interface {
+    matches(n *Notes, note *Note) bool
+}
+
+type andNode struct {
+    left queryNode
+    right queryNode
+}
+
+func (q *andNode) matches(n *Notes, note *Note) (bool) {
+    return q.left.matches(n, note) && q.right.matches(n, note)
+}
+
+type orNode struct {
+    left queryNode
+    right queryNode
+}
+
+func (q *orNode) matches(n *Notes, note *Note) (bool) {
+    return q.left.matches(n, note) || q.right.matches(n, note)
+}
+
+type notNode struct {
+    node queryNode
+}
+
+func (q *notNode) matches(n *Notes, note *Note) (bool) {
+    return !q.node.matches(n, note)
+}
+
+type termNode struct {
+    match func(n *Notes, note *Note) bool
+}
+
+func (q *termNode) matches(n *Notes, note *Note) (bool) {
+    return q.match(n, note)
+}
+
+type queryParser struct {
+    tokens []string
+    pos int
+    dueFormat string
+}
+
+func ParseQuery(str string, dueFormat string) (*Query, error) {
+    p := queryParser{tokens: tokenizeQuery(str), dueFormat: dueFormat}
+    if len(p.tokens) == 0 {
+        return nil, errors.New("Empty query given")
+    }
+
+    root, err := p.parseOr()
+    if err != nil {
+        return nil, err
+    }
+
+    if p.pos < len(p.tokens) {
+        return nil, errors.New("Unexpected \"" + p.tokens[p.pos] + "\" in query")
+    }
+    return &Query{root: root}, nil
+}
+
+func (q *Query) Matches(n *Notes, note *Note) (bool) {
+    return q.root.matches(n, note)
+}
+
+func (n *Notes) QueryNotes(q *Query, notes []*Note) []*Note {
+    var ret []*Note
+    for _, note := range notes {
+        if q.Matches(n, note) {
+            ret = append(ret, note)
+        }
+    }
+    return ret
+}
+
+// Splits query to words, parentheses and quoted strings
+func tokenizeQuery(str string) ([]string) {
+    var ret []string
+    current := ""
+    quoted := false
+    for _, char := range str {
+        switch {
+            case char == '"':
+                quoted = !quoted
+                current += string(char)
+            case quoted:
+                current += string(char)
+            case char == '(' || char == ')':
+                if len(current) > 0 {
+                    ret = append(ret, current)
+                    current = ""
+                }
+                ret = append(ret, string(char))
+            case char == ' ' || char == '\t':
+                if len(current) > 0 {
+                    ret = append(ret, current)
+                    current = ""
+                }
+            default:
+                current += string(char)
+        }
+    }
+
+    if len(current) > 0 {
+        ret = append(ret, current)
+    }
+    return ret
+}
+
+func (p *queryParser) peek() (string) {
+    if p.pos >= len(p.tokens) {
+        return ""
+    }
+    return p.tokens[p.pos]
+}
+
+func (p *queryParser) parseOr() (queryNode, error) {
+    left, err := p.parseAnd()
+    if err != nil {
+        return nil, err
+    }
+
+    for strings.ToUpper(p.peek()) == "OR" {
+        p.pos++
+        right, err := p.parseAnd()
+        if err != nil {
+            return nil, err
+        }
+        left = &orNode{left: left, right: right}
+    }
+    return left, nil
+}
+
+func (p *queryParser) parseAnd() (queryNode, error) {
+    left, err := p.parseNot()
+    if err != nil {
+        return nil, err
+    }
+
+    for {
+        token := p.peek()
+        if len(token) == 0 || token == ")" || strings.ToUpper(token) == "OR" {
+            break
+        }
+
+        if strings.ToUpper(token) == "AND" {
+            p.pos++
+        }
+
+        right, err := p.parseNot()
+        if err != nil {
+            return nil, err
+        }
+        left = &andNode{left: left, right: right}
+    }
+    return left, nil
+}
+
+func (p *queryParser) parseNot() (queryNode, error) {
+    if strings.ToUpper(p.peek()) == "NOT" {
+        p.pos++
+        node, err := p.parseNot()
+        if err != nil {
+            return nil, err
+        }
+        return &notNode{node: node}, nil
+    }
+    return p.parsePrimary()
+}
+
+func (p *queryParser) parsePrimary() (queryNode, error) {
+    token := p.peek()
+    if len(token) == 0 {
+        return nil, errors.New("Unexpected end of query")
+    }
+    p.pos++
+
+    if token == "(" {
+        node, err := p.parseOr()
+        if err != nil {
+            return nil, err
+        }
+
+        if p.peek() != ")" {
+            return nil, errors.New("Missing closing parenthesis in query")
+        }
+        p.pos++
+        return node, nil
+    }
+
+    if token == ")" {
+        return nil, errors.New("Unexpected closing parenthesis in query")
+    }
+
+    return p.parseTerm(token)
+}
+
+func (p *queryParser) parseTerm(token string) (queryNode, error) {
+    lower := strings.ToLower(token)
+    switch lower {
+        case "done":
+            return &termNode{func(n *Notes, note *Note) bool {
+                return note.Done
+            }}, nil
+        case "open":
+            return &termNode{func(n *Notes, note *Note) bool {
+                return !note.Done
+            }}, nil
+        case "blocked":
+            return &termNode{func(n *Notes, note *Note) bool {
+                return n.IsBlocked(note)
+            }}, nil
+        case "mine":
+            return &termNode{func(n *Notes, note *Note) bool {
+                return note.IsAssignedTo(n.GetCurrentUser())
+            }}, nil
+        case "deferred":
+            return &termNode{func(n *Notes, note *Note) bool {
+                return note.IsDeferred()
+            }}, nil
+    }
+
+    if isPriorityTerm(lower) {
+        return p.parsePriority(lower[len("prio"):])
+    }
+
+    parts := strings.SplitN(token, ":", 2)
+    if len(parts) == 2 {
+        value := strings.Trim(parts[1], "\"")
+        switch strings.ToLower(parts[0]) {
+            case "tag":
+                return &termNode{func(n *Notes, note *Note) bool {
+                    return n.NoteHasTag(note, value)
+                }}, nil
+            case "assignee":
+                return &termNode{func(n *Notes, note *Note) bool {
+                    who, err := n.ResolveUser(value)
+                    return err == nil && note.IsAssignedTo(who)
+                }}, nil
+            case "id":
+                ids, _, err := ParseIdList(value)
+                if err != nil {
+                    return nil, err
+                }
+                return &termNode{func(n *Notes, note *Note) bool {
+                    for _, id := range ids {
+                        if note.Id == id {
+                            return true
+                        }
+                    }
+                    return false
+                }}, nil
+            case "parent":
+                id, err := strconv.ParseUint(value, 10, 32)
+                if err != nil {
+                    return nil, errors.New("Invalid parent id in query: " + value)
+                }
+                return &termNode{func(n *Notes, note *Note) bool {
+                    return n.IsDescendant(note, uint(id))
+                }}, nil
+            case "due":
+                return p.parseDue(value)
+            case "status":
+                return &termNode{func(n *Notes, note *Note) bool {
+                    return normalizeStatus(n.GetStatus(note)) == normalizeStatus(value)
+                }}, nil
+        }
+    }
+
+    search := strings.Trim(token, "\"")
+    return &termNode{func(n *Notes, note *Note) bool {
+        return note.MatchesSearch(search)
+    }}, nil
+}
+
+// Returns true for priority terms such as "prio:4" and "prio>2". Words
+// such as "priority" are searched from the content instead.
+func isPriorityTerm(lower string) (bool) {
+    if !strings.HasPrefix(lower, "prio") || len(lower) == len("prio") {
+        return false
+    }
+    return strings.ContainsAny(lower[len("prio"):len("prio")+1], ":=<>")
+}
+
+func (p *queryParser) parsePriority(str string) (queryNode, error) {
+    op := ">="
+    for _, o := range []string{">=", "<=", ":", "=", ">", "<"} {
+        if strings.HasPrefix(str, o) {
+            op = o
+            str = str[len(o):]
+            break
+        }
+    }
+
+    prio, err := strconv.ParseUint(str, 10, 32)
+    if err != nil {
+        return nil, errors.New("Invalid priority in query: " + str)
+    }
+
+    value := uint(prio)
+    return &termNode{func(n *Notes, note *Note) bool {
+        switch op {
+            case "=":
+                return note.Priority == value
+            case ">":
+                return note.Priority > value
+            case "<":
+                return note.Priority < value
+            case "<=":
+                return note.Priority <= value
+        }
+        return note.Priority >= value
+    }}, nil
+}
+
+func (p *queryParser) parseDue(value string) (queryNode, error) {
+    today := GetToday()
+
+    var day time.Time
+    switch strings.ToLower(value) {
+        case "none":
+            return &termNode{func(n *Notes, note *Note) bool {
+                return note.Due.IsZero()
+            }}, nil
+        case "any":
+            return &termNode{func(n *Notes, note *Note) bool {
+                return !note.Due.IsZero()
+            }}, nil
+        case "overdue":
+            return &termNode{func(n *Notes, note *Note) bool {
+                return !note.Due.IsZero() && RoundTimeToDay(note.Due).Before(today)
+            }}, nil
+        default:
+            due, err := ParseDate(value, p.dueFormat)
+            if err != nil {
+                return nil, errors.New("Invalid due date in query. Use today, tomorrow, overdue, none, any or date in format " + p.dueFormat)
+            }
+            day = RoundTimeToDay(due)
+    }
+
+    return &termNode{func(n *Notes, note *Note) bool {
+        return !note.Due.IsZero() && RoundTimeToDay(note.Due).Equal(day)
+    }}, nil
+}
+
+// Parses list of ids and id ranges such as "3,5,10-14". Ids are returned
+// once in the order they were given. Ids given only inside ranges are
+// returned also in a set so that missing notes in them can be skipped.
+func ParseIdList(str string) ([]uint, map[uint]bool, error) {
+    var ret []uint
+    seen := map[uint]bool{}
+    ranged := map[uint]bool{}
+    for _, part := range strings.Split(str, ",") {
+        part = strings.Trim(part, " ")
+        if len(part) == 0 {
+            continue
+        }
+
+        bounds := strings.SplitN(part, "-", 2)
+        start, err := strconv.ParseUint(bounds[0], 10, 32)
+        if err != nil {
+            return nil, nil, errors.New("Invalid note id: " + part)
+        }
+
+        end := start
+        if len(bounds) == 2 {
+            end, err = strconv.ParseUint(bounds[1], 10, 32)
+            if err != nil || end < start {
+                return nil, nil, errors.New("Invalid range of note ids: " + part)
+            }
+            if end - start >= MAX_ID_RANGE {
+                return nil, nil, errors.New("Range of note ids " + part + " is too large. At most " + strconv.Itoa(MAX_ID_RANGE) + " ids can be given in a range")
+            }
+        }
+
+        for id := start; id <= end; id++ {
+            if len(bounds) == 2 && !seen[uint(id)] {
+                ranged[uint(id)] = true
+            } else if len(bounds) == 1 {
+                delete(ranged, uint(id))
+            }
+
+            if !seen[uint(id)] {
+                seen[uint(id)] = true
+                ret = append(ret, uint(id))
+            }
+        }
+    }
+
+    if len(ret) == 0 {
+        return nil, nil, errors.New("No note ids given")
+    }
+    return ret, ranged, nil
+}
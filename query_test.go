@@ -0,0 +1,165 @@
+package main
+
+import (
+    "reflect"
+    "testing"
+)
+
+// Returns notes with default configuration that are not connected to Drive
+func newTestNotes(notes ...Note) (*Notes) {
+    config := NewConfiguration()
+    n := &Notes{config: &config}
+    for _, note := range notes {
+        n.notes = append(n.notes, note)
+    }
+    n.takeSnapshot()
+    return n
+}
+
+func getIds(notes []*Note) ([]uint) {
+    ret := []uint{}
+    for _, note := range notes {
+        ret = append(ret, note.Id)
+    }
+    return ret
+}
+
+func TestParseIdList(t *testing.T) {
+    tests := []struct {
+        str string
+        ids []uint
+        ranged []uint
+        err bool
+    }{
+        {"3", []uint{3}, nil, false},
+        {"3,5,10-12", []uint{3, 5, 10, 11, 12}, []uint{10, 11, 12}, false},
+        {"3,3", []uint{3}, nil, false},
+        {"1-3,2", []uint{1, 2, 3}, []uint{1, 3}, false},
+        {"2,1-3", []uint{2, 1, 3}, []uint{1, 3}, false},
+        {"1-3,2-4", []uint{1, 2, 3, 4}, []uint{1, 2, 3, 4}, false},
+        {" 4 , 5 ", []uint{4, 5}, nil, false},
+        {"", nil, nil, true},
+        {"a", nil, nil, true},
+        {"5-3", nil, nil, true},
+        {"1-4000000000", nil, nil, true},
+        {"-3", nil, nil, true},
+    }
+
+    for _, test := range tests {
+        ids, ranged, err := ParseIdList(test.str)
+        if test.err {
+            if err == nil {
+                t.Errorf("ParseIdList(%q) should fail", test.str)
+            }
+            continue
+        }
+        if err != nil {
+            t.Errorf("ParseIdList(%q) failed: %v", test.str, err)
+            continue
+        }
+        if !reflect.DeepEqual(ids, test.ids) {
+            t.Errorf("ParseIdList(%q) = %v, want %v", test.str, ids, test.ids)
+        }
+
+        expected := map[uint]bool{}
+        for _, id := range test.ranged {
+            expected[id] = true
+        }
+        if !reflect.DeepEqual(ranged, expected) {
+            t.Errorf("ParseIdList(%q) ranged = %v, want %v", test.str, ranged, expected)
+        }
+    }
+}
+
+func TestGetNotesFromArgs(t *testing.T) {
+    n := newTestNotes(Note{Id: 10}, Note{Id: 11}, Note{Id: 13}, Note{Id: 14})
+    config := NewConfiguration()
+
+    tests := []struct {
+        args []string
+        ids []uint
+        err bool
+    }{
+        {[]string{"10-14"}, []uint{10, 11, 13, 14}, false},
+        {[]string{"11,11"}, []uint{11}, false},
+        {[]string{"10-11,11"}, []uint{10, 11}, false},
+        {[]string{"12"}, nil, true},
+        {[]string{"12,13"}, nil, true},
+        {[]string{"20-25"}, nil, true},
+    }
+
+    for _, test := range tests {
+        sel, err := getNotesFromArgs(test.args, n, &config)
+        if test.err {
+            if err == nil {
+                t.Errorf("getNotesFromArgs(%v) should fail", test.args)
+            }
+            continue
+        }
+        if err != nil {
+            t.Errorf("getNotesFromArgs(%v) failed: %v", test.args, err)
+            continue
+        }
+        if !reflect.DeepEqual(getIds(sel.notes), test.ids) {
+            t.Errorf("getNotesFromArgs(%v) = %v, want %v", test.args, getIds(sel.notes), test.ids)
+        }
+    }
+}
+
+func TestParseQuery(t *testing.T) {
+    today := GetToday()
+    n := newTestNotes(
+        Note{Id: 1, Content: "Fix priority bug", Priority: 5, Tags: []string{"work/backend"}, Due: today},
+        Note{Id: 2, Content: "Write docs", Priority: 2, Tags: []string{"work"}, Done: true},
+        Note{Id: 3, Content: "Buy milk", Priority: 3, Tags: []string{"home"}, Due: today.AddDate(0, 0, -1)},
+        Note{Id: 4, Content: "Plan sprint", Priority: 4, Tags: []string{"sprint12"}, Parent: 1},
+    )
+
+    tests := []struct {
+        query string
+        ids []uint
+        err bool
+    }{
+        {"tag:work", []uint{1, 2}, false},
+        {"tag:work AND NOT done", []uint{1}, false},
+        {"tag:work OR tag:home", []uint{1, 2, 3}, false},
+        {"NOT (tag:work OR tag:home)", []uint{4}, false},
+        {"prio:4", []uint{1, 4}, false},
+        {"prio>=4", []uint{1, 4}, false},
+        {"prio=4", []uint{4}, false},
+        {"prio<3", []uint{2}, false},
+        {"priority", []uint{1}, false},
+        {"done", []uint{2}, false},
+        {"open AND id:1-3", []uint{1, 3}, false},
+        {"id:4,4", []uint{4}, false},
+        {"parent:1", []uint{4}, false},
+        {"due:today", []uint{1}, false},
+        {"due:overdue", []uint{3}, false},
+        {"due:none", []uint{2, 4}, false},
+        {"status:done", []uint{2}, false},
+        {"milk", []uint{3}, false},
+        {"prio:x", nil, true},
+        {"(tag:work", nil, true},
+        {"tag:work)", nil, true},
+        {"due:someday", nil, true},
+    }
+
+    for _, test := range tests {
+        query, err := ParseQuery(test.query, n.config.DueFormat)
+        if test.err {
+            if err == nil {
+                t.Errorf("ParseQuery(%q) should fail", test.query)
+            }
+            continue
+        }
+        if err != nil {
+            t.Errorf("ParseQuery(%q) failed: %v", test.query, err)
+            continue
+        }
+
+        ids := getIds(n.QueryNotes(query, n.GetNotes()))
+        if !reflect.DeepEqual(ids, test.ids) {
+            t.Errorf("ParseQuery(%q) matched %v, want %v", test.query, ids, test.ids)
+        }
+    }
+}
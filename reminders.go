@@ -0,0 +1,339 @@
+package main
+
+import (
+    "errors"
+    "fmt"
+    "io"
+    "log"
+    "os"
+    "os/exec"
+    "strconv"
+    "strings"
+    "syscall"
+    "time"
+)
+
+// Reminder of a note. Relative reminders are counted from the start of the
+// due day of the note as due dates have no time, so "1h before due" is
+// delivered at 23:00 on the previous day.
+type Reminder struct {
+    At time.Time `json:"at"`
+    BeforeDue time.Duration `json:"before_due"`
+    Relative bool `json:"relative"`
+    Owner string `json:"owner"`
+    Fired bool `json:"fired"`
+    // Due date of the note when a relative reminder was delivered
+    FiredDue time.Time `json:"fired_due"`
+}
+
+// Reminder that is due to be delivered
+type ReminderEvent struct {
+    NoteId uint
+    Title string
+    Due string
+    Time time.Time
+    Message string
+}
+
+// Delivers reminders somewhere
+type ReminderSink interface {
+    Notify(event *ReminderEvent) (error)
+}
+
+// Parses reminder time. Supported formats are "due", "1h before due",
+// "in 2h", "2h" and times and dates in the given formats.
+func ParseReminder(str string, timeFormat string, dueFormat string) (Reminder, error) {
+    ret := Reminder{}
+    lower := strings.ToLower(strings.Trim(str, " "))
+
+    if lower == "due" || lower == "at due" {
+        ret.Relative = true
+        return ret, nil
+    }
+
+    if strings.HasSuffix(lower, "before due") {
+        d, err := ParseDuration(strings.TrimSuffix(lower, "before due"))
+        if err != nil {
+            return ret, err
+        }
+        ret.Relative = true
+        ret.BeforeDue = d
+        return ret, nil
+    }
+
+    d, err := ParseDuration(strings.TrimPrefix(lower, "in "))
+    if err == nil {
+        ret.At = time.Now().Add(d)
+        return ret, nil
+    }
+
+    for _, format := range []string{timeFormat, dueFormat} {
+        t, err := time.ParseInLocation(format, str, time.Local)
+        if err == nil {
+            ret.At = t
+            return ret, nil
+        }
+    }
+    return ret, errors.New("Invalid reminder time given. Use for example \"1h before due\", \"in 2h\" or time in format " + timeFormat)
+}
+
+// Returns the time the reminder should be delivered or zero time if the
+// reminder is relative and the note has no due date
+func (r *Reminder) GetTime(note *Note) (time.Time) {
+    if !r.Relative {
+        return r.At
+    }
+
+    if note.Due.IsZero() {
+        return time.Time{}
+    }
+
+    due := time.Date(note.Due.Year(), note.Due.Month(), note.Due.Day(), 0, 0, 0, 0, time.Local)
+    return due.Add(-r.BeforeDue)
+}
+
+// Returns true if the reminder has been delivered. Relative reminders are
+// delivered again when the due date of the note changes.
+func (r *Reminder) IsFired(note *Note) (bool) {
+    if !r.Fired {
+        return false
+    }
+    // Reminders delivered before the due date was recorded stay delivered
+    if !r.Relative || r.FiredDue.IsZero() {
+        return true
+    }
+    return r.FiredDue.Equal(note.Due)
+}
+
+// Marks the reminder delivered for the current due date of the note
+func (r *Reminder) SetFired(note *Note) {
+    r.Fired = true
+    r.FiredDue = time.Time{}
+    if r.Relative {
+        r.FiredDue = note.Due
+    }
+}
+
+func (r *Reminder) Describe(timeFormat string) (string) {
+    if !r.Relative {
+        return r.At.Format(timeFormat)
+    }
+
+    if r.BeforeDue == 0 {
+        return "at due"
+    }
+    return FormatDuration(r.BeforeDue) + " before due"
+}
+
+func (n *Notes) AddReminder(note *Note, reminder Reminder) {
+    if len(reminder.Owner) == 0 {
+        reminder.Owner = n.GetCurrentUser()
+    }
+    note.Reminders = append(note.Reminders, reminder)
+}
+
+// Reschedules the latest delivered reminder of the note or the next
+// reminder if none of them has been delivered yet
+func (n *Notes) SnoozeReminder(note *Note, d time.Duration) {
+    idx := -1
+    for i, _ := range note.Reminders {
+        r := &note.Reminders[i]
+        if !r.IsFired(note) {
+            continue
+        }
+        if idx < 0 || r.GetTime(note).After(note.Reminders[idx].GetTime(note)) {
+            idx = i
+        }
+    }
+
+    if idx < 0 {
+        for i, _ := range note.Reminders {
+            r := &note.Reminders[i]
+            if idx < 0 || r.GetTime(note).Before(note.Reminders[idx].GetTime(note)) {
+                idx = i
+            }
+        }
+    }
+
+    if idx < 0 {
+        n.AddReminder(note, Reminder{At: time.Now().Add(d)})
+        return
+    }
+
+    r := &note.Reminders[idx]
+    r.At = time.Now().Add(d)
+    r.Relative = false
+    r.BeforeDue = 0
+    r.Fired = false
+    r.FiredDue = time.Time{}
+}
+
+// Returns next reminder of the note that has not been delivered yet
+func (n *Notes) GetNextReminder(note *Note) (*Reminder) {
+    var ret *Reminder
+    for i, _ := range note.Reminders {
+        r := &note.Reminders[i]
+        if r.IsFired(note) || r.GetTime(note).IsZero() {
+            continue
+        }
+        if ret == nil || r.GetTime(note).Before(ret.GetTime(note)) {
+            ret = r
+        }
+    }
+    return ret
+}
+
+// Returns reminders of the current user that should be delivered at given
+// time. Reminders of done notes are not delivered.
+func (n *Notes) GetDueReminders(now time.Time) ([]*Note, []*Reminder) {
+    var notes []*Note
+    var reminders []*Reminder
+    user := n.GetCurrentUser()
+    for _, note := range n.FilterDoneNotes(n.GetNotes()) {
+        for i, _ := range note.Reminders {
+            r := &note.Reminders[i]
+            if r.IsFired(note) || (len(r.Owner) > 0 && !strings.EqualFold(r.Owner, user)) {
+                continue
+            }
+
+            t := r.GetTime(note)
+            if t.IsZero() || t.After(now) {
+                continue
+            }
+            notes = append(notes, note)
+            reminders = append(reminders, r)
+        }
+    }
+    return notes, reminders
+}
+
+// Writes reminders to a log
+type LogSink struct {
+    logger *log.Logger
+}
+
+func NewLogSink(w io.Writer) (*LogSink) {
+    return &LogSink{logger: log.New(w, "", log.LstdFlags)}
+}
+
+func (s *LogSink) Notify(event *ReminderEvent) (error) {
+    s.logger.Println(event.Message)
+    return nil
+}
+
+// Runs a command for reminders. Reminder is given to the command in
+// environment variables.
+type CommandSink struct {
+    Command string
+}
+
+func (s *CommandSink) Notify(event *ReminderEvent) (error) {
+    cmd := exec.Command("sh", "-c", s.Command)
+    cmd.Env = append(os.Environ(),
+        "GDRIVE_NOTES_ID=" + strconv.Itoa(int(event.NoteId)),
+        "GDRIVE_NOTES_TITLE=" + event.Title,
+        "GDRIVE_NOTES_DUE=" + event.Due,
+        "GDRIVE_NOTES_MESSAGE=" + event.Message)
+
+    out, err := cmd.CombinedOutput()
+    if err != nil {
+        msg := "Reminder command failed: " + err.Error()
+        output := strings.Trim(string(out), "\n")
+        if len(output) > 0 {
+            msg += ": " + output
+        }
+        return errors.New(msg)
+    }
+    return nil
+}
+
+// Writes reminders as lines to a named pipe. Pipe is opened without
+// blocking so reminders are dropped when nobody is reading it.
+type FifoSink struct {
+    Path string
+}
+
+func (s *FifoSink) Notify(event *ReminderEvent) (error) {
+    f, err := os.OpenFile(s.Path, os.O_WRONLY|os.O_APPEND|syscall.O_NONBLOCK, 0)
+    if err != nil {
+        if pathErr, ok := err.(*os.PathError); ok && pathErr.Err == syscall.ENXIO {
+            return errors.New("Nobody is reading reminders from " + s.Path)
+        }
+        return err
+    }
+    defer f.Close()
+
+    _, err = fmt.Fprintln(f, event.Message)
+    return err
+}
+
+// Watches the notes and delivers reminders to the sinks
+type ReminderDaemon struct {
+    Notes *Notes
+    Config *Configuration
+    Sinks []ReminderSink
+    Interval time.Duration
+    delivered map[string]bool
+}
+
+func (d *ReminderDaemon) Run() (error) {
+    if len(d.Sinks) == 0 {
+        return errors.New("No reminder sinks given")
+    }
+
+    for {
+        err := d.Check(time.Now())
+        if err != nil {
+            log.Printf("Checking reminders failed: %v", err)
+        }
+        time.Sleep(d.Interval)
+    }
+}
+
+// Delivers reminders due at given time and saves them delivered
+func (d *ReminderDaemon) Check(now time.Time) (error) {
+    if d.delivered == nil {
+        d.delivered = map[string]bool{}
+    }
+
+    _, err := d.Notes.Reload()
+    if err != nil {
+        return err
+    }
+
+    notes, reminders := d.Notes.GetDueReminders(now)
+    if len(reminders) == 0 {
+        return nil
+    }
+
+    for i, reminder := range reminders {
+        note := notes[i]
+        t := reminder.GetTime(note)
+
+        // Reminders are remembered in case saving them delivered fails
+        key := strconv.Itoa(int(note.Id)) + "-" + strconv.FormatInt(t.Unix(), 10)
+        if !d.delivered[key] {
+            event := d.newEvent(note, t)
+            for _, sink := range d.Sinks {
+                err = sink.Notify(event)
+                if err != nil {
+                    log.Printf("Delivering reminder of note %v failed: %v", note.Id, err)
+                }
+            }
+            d.delivered[key] = true
+        }
+        reminder.SetFired(note)
+    }
+
+    return d.Notes.SaveNotes()
+}
+
+func (d *ReminderDaemon) newEvent(note *Note, t time.Time) (*ReminderEvent) {
+    event := ReminderEvent{NoteId: note.Id, Title: note.GetTitle(), Time: t}
+    event.Message = "Reminder: #" + strconv.Itoa(int(note.Id)) + " " + note.GetTitle()
+    if !note.Due.IsZero() {
+        event.Due = note.Due.Format(d.Config.DueFormat)
+        event.Message += " (due " + event.Due + ")"
+    }
+    return &event
+}
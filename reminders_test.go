@@ -0,0 +1,197 @@
+package main
+
+import (
+    "bytes"
+    "io/ioutil"
+    "os"
+    "os/exec"
+    "path/filepath"
+    "strings"
+    "syscall"
+    "testing"
+    "time"
+)
+
+func TestReminderSinks(t *testing.T) {
+    dir, err := ioutil.TempDir("", "reminders")
+    if err != nil {
+        t.Fatal(err)
+    }
+    defer os.RemoveAll(dir)
+
+    if exec.Command("mkfifo", filepath.Join(dir, "fifo"), filepath.Join(dir, "unread")).Run() != nil {
+        t.Skip("mkfifo is not available")
+    }
+
+    event := &ReminderEvent{NoteId: 12, Title: "Fix bug", Due: "14.10.2026", Message: "Reminder: #12 Fix bug"}
+    var logged bytes.Buffer
+
+    type sinkTest struct {
+        name string
+        sink ReminderSink
+        // Returns what the sink delivered
+        read func() (string)
+        expected string
+        err string
+    }
+
+    tests := []sinkTest{
+        {
+            "log",
+            NewLogSink(&logged),
+            func() (string) { return logged.String() },
+            "Reminder: #12 Fix bug\n",
+            "",
+        },
+        {
+            "command",
+            &CommandSink{Command: `echo "$GDRIVE_NOTES_ID $GDRIVE_NOTES_TITLE $GDRIVE_NOTES_DUE" > ` + filepath.Join(dir, "out")},
+            func() (string) {
+                data, _ := ioutil.ReadFile(filepath.Join(dir, "out"))
+                return string(data)
+            },
+            "12 Fix bug 14.10.2026\n",
+            "",
+        },
+        {
+            "failing command",
+            &CommandSink{Command: "echo oops; exit 3"},
+            nil,
+            "",
+            "Reminder command failed: exit status 3: oops",
+        },
+        {
+            "fifo without reader",
+            &FifoSink{Path: filepath.Join(dir, "unread")},
+            nil,
+            "",
+            "Nobody is reading reminders from",
+        },
+        {
+            "missing fifo",
+            &FifoSink{Path: filepath.Join(dir, "missing")},
+            nil,
+            "",
+            "no such file",
+        },
+    }
+
+    // Reader of the fifo has to be open before notifying
+    reader, err := os.OpenFile(filepath.Join(dir, "fifo"), os.O_RDONLY|syscall.O_NONBLOCK, 0)
+    if err != nil {
+        t.Fatal(err)
+    }
+    defer reader.Close()
+    tests = append(tests, sinkTest{
+        "fifo",
+        &FifoSink{Path: filepath.Join(dir, "fifo")},
+        func() (string) {
+            buf := make([]byte, 100)
+            n, _ := reader.Read(buf)
+            return string(buf[:n])
+        },
+        "Reminder: #12 Fix bug\n",
+        "",
+    })
+
+    for _, test := range tests {
+        done := make(chan error, 1)
+        go func() {
+            done <- test.sink.Notify(event)
+        }()
+
+        select {
+            case err = <-done:
+                break
+            case <-time.After(5 * time.Second):
+                t.Fatalf("%v: Notify blocked", test.name)
+        }
+
+        if len(test.err) > 0 {
+            if err == nil || !strings.Contains(err.Error(), test.err) {
+                t.Errorf("%v: Notify() returned %v, want error containing %q", test.name, err, test.err)
+            }
+            continue
+        }
+        if err != nil {
+            t.Errorf("%v: Notify() failed: %v", test.name, err)
+            continue
+        }
+
+        out := test.read()
+        if !strings.HasSuffix(out, test.expected) {
+            t.Errorf("%v: delivered %q, want %q", test.name, out, test.expected)
+        }
+    }
+}
+
+func TestParseReminder(t *testing.T) {
+    due := time.Date(2026, 10, 14, 0, 0, 0, 0, time.Local)
+    note := &Note{Due: due}
+
+    tests := []struct {
+        str string
+        at time.Time
+        err bool
+    }{
+        {"due", due, false},
+        {"1h before due", due.Add(-time.Hour), false},
+        {"2d before due", due.AddDate(0, 0, -2), false},
+        {"-1h before due", time.Time{}, true},
+        {"14.10.2026 09:30", time.Date(2026, 10, 14, 9, 30, 0, 0, time.Local), false},
+        {"someday", time.Time{}, true},
+    }
+
+    for _, test := range tests {
+        reminder, err := ParseReminder(test.str, "02.01.2006 15:04", "02.01.2006")
+        if test.err {
+            if err == nil {
+                t.Errorf("ParseReminder(%q) should fail", test.str)
+            }
+            continue
+        }
+        if err != nil {
+            t.Errorf("ParseReminder(%q) failed: %v", test.str, err)
+            continue
+        }
+        if !reminder.GetTime(note).Equal(test.at) {
+            t.Errorf("ParseReminder(%q) is at %v, want %v", test.str, reminder.GetTime(note), test.at)
+        }
+    }
+}
+
+func TestReminderFiresAgainWhenDueChanges(t *testing.T) {
+    due := time.Date(2026, 10, 14, 0, 0, 0, 0, time.UTC)
+    n := newTestNotes(Note{Id: 1, Due: due, Reminders: []Reminder{
+        {Relative: true, BeforeDue: time.Hour},
+        {At: time.Date(2026, 10, 12, 9, 0, 0, 0, time.Local)},
+    }})
+    note := n.FindNote(1)
+
+    _, reminders := n.GetDueReminders(time.Date(2026, 10, 14, 12, 0, 0, 0, time.Local))
+    if len(reminders) != 2 {
+        t.Fatalf("GetDueReminders() returned %v reminders, want 2", len(reminders))
+    }
+    for _, r := range reminders {
+        r.SetFired(note)
+    }
+
+    tests := []struct {
+        due time.Time
+        now time.Time
+        count int
+    }{
+        {due, time.Date(2026, 10, 14, 12, 0, 0, 0, time.Local), 0},
+        // Only the relative reminder follows the new due date
+        {due.AddDate(0, 0, 3), time.Date(2026, 10, 14, 12, 0, 0, 0, time.Local), 0},
+        {due.AddDate(0, 0, 3), time.Date(2026, 10, 17, 12, 0, 0, 0, time.Local), 1},
+    }
+
+    for _, test := range tests {
+        note.Due = test.due
+        _, reminders := n.GetDueReminders(test.now)
+        if len(reminders) != test.count {
+            t.Errorf("GetDueReminders(%v) with due %v returned %v reminders, want %v", test.now, test.due, len(reminders), test.count)
+        }
+    }
+}
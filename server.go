@@ -0,0 +1,455 @@
+package main
+
+import (
+    "crypto/rand"
+    "crypto/subtle"
+    "encoding/hex"
+    "encoding/json"
+    "errors"
+    "log"
+    "net/http"
+    "os"
+    "os/signal"
+    "sort"
+    "strconv"
+    "strings"
+    "sync"
+    "syscall"
+    "time"
+)
+
+const maxRequestSize = 1 << 20
+
+// Failed saves are retried with increasing delay until the delay reaches
+// the maximum. Saving is never given up as the changes have been accepted.
+const maxSaveBackoff = 10 * time.Minute
+
+// Serves the notes over HTTP as JSON. Notes are guarded with a mutex and
+// changes are saved to Drive once no changes have been made during the save
+// delay. Changes are rejected while saving the previous ones fails.
+type NotesServer struct {
+    Notes *Notes
+    Config *Configuration
+    Addr string
+    Token string
+    SaveDelay time.Duration
+    mutex sync.Mutex
+    saveTimer *time.Timer
+    unsavedModifications bool
+    modifications uint64
+    lastReload time.Time
+    savesInProgress int
+    saveErr error
+    saveFailures int
+}
+
+// Fields of a note that can be given when creating or updating notes. Fields
+// that are left out are not changed.
+type NoteInput struct {
+    Content *string `json:"content"`
+    Priority *uint `json:"priority"`
+    Tags *[]string `json:"tags"`
+    Due *string `json:"due"`
+    Status *string `json:"status"`
+    Done *bool `json:"done"`
+    Assignee *string `json:"assignee"`
+    Estimate *string `json:"estimate"`
+}
+
+// Returns random token for authenticating to the server
+func GenerateApiToken() (string, error) {
+    b := make([]byte, 32)
+    _, err := rand.Read(b)
+    if err != nil {
+        return "", err
+    }
+    return hex.EncodeToString(b), nil
+}
+
+func (s *NotesServer) Run() (error) {
+    if len(s.Token) == 0 {
+        return errors.New("No API token given")
+    }
+
+    mux := http.NewServeMux()
+    mux.HandleFunc("/notes", s.authorize(s.handleNotes))
+    mux.HandleFunc("/notes/", s.authorize(s.handleNote))
+    mux.HandleFunc("/tags", s.authorize(s.handleTags))
+    server := &http.Server{Addr: s.Addr, Handler: mux}
+
+    // Unsaved changes are saved before exiting
+    ch := make(chan os.Signal, 1)
+    signal.Notify(ch, os.Interrupt, syscall.SIGTERM)
+    go func() {
+        <-ch
+        server.Close()
+    }()
+
+    err := server.ListenAndServe()
+    if err != http.ErrServerClosed {
+        return err
+    }
+    return s.save()
+}
+
+func (s *NotesServer) authorize(f http.HandlerFunc) (http.HandlerFunc) {
+    return func(w http.ResponseWriter, r *http.Request) {
+        header := r.Header.Get("Authorization")
+        token := strings.TrimPrefix(header, "Bearer ")
+        if token == header || subtle.ConstantTimeCompare([]byte(token), []byte(s.Token)) != 1 {
+            w.Header().Set("WWW-Authenticate", "Bearer")
+            writeError(w, http.StatusUnauthorized, "Invalid or missing token")
+            return
+        }
+        f(w, r)
+    }
+}
+
+// Handles listing and creating notes
+func (s *NotesServer) handleNotes(w http.ResponseWriter, r *http.Request) {
+    s.mutex.Lock()
+    defer s.mutex.Unlock()
+    s.reload()
+
+    switch(r.Method) {
+        case http.MethodGet:
+            notes := s.Notes.GetNotes()
+            q := r.URL.Query().Get("q")
+            if len(q) > 0 {
+                query, err := ParseQuery(q, s.Config.DueFormat)
+                if err != nil {
+                    writeError(w, http.StatusBadRequest, err.Error())
+                    return
+                }
+                notes = s.Notes.QueryNotes(query, notes)
+            }
+
+            order := []string{"id"}
+            if len(r.URL.Query().Get("order")) > 0 {
+                order = strings.Split(r.URL.Query().Get("order"), ",")
+            }
+            s.Notes.OrderNotes(order, notes)
+
+            // Empty list is returned instead of null
+            ret := make([]*Note, 0, len(notes))
+            writeJson(w, http.StatusOK, append(ret, notes...))
+            break
+
+        case http.MethodPost:
+            if !s.checkSaved(w) {
+                return
+            }
+
+            input, err := readNoteInput(w, r)
+            if err != nil {
+                writeError(w, http.StatusBadRequest, err.Error())
+                return
+            }
+            if input.Content == nil || len(strings.Trim(*input.Content, " \n")) == 0 {
+                writeError(w, http.StatusBadRequest, "Note content is required")
+                return
+            }
+
+            note := Note{Priority: s.Config.DefaultPriority}
+            err = s.applyInput(&note, input)
+            if err != nil {
+                writeError(w, http.StatusBadRequest, err.Error())
+                return
+            }
+
+            id := s.Notes.AddNote(note)
+            s.scheduleSave()
+            writeJson(w, http.StatusCreated, s.Notes.FindNote(id))
+            break
+
+        default:
+            w.Header().Set("Allow", "GET, POST")
+            writeError(w, http.StatusMethodNotAllowed, "Method " + r.Method + " is not allowed")
+    }
+}
+
+// Handles getting, updating and deleting single note
+func (s *NotesServer) handleNote(w http.ResponseWriter, r *http.Request) {
+    id, err := strconv.ParseUint(strings.TrimPrefix(r.URL.Path, "/notes/"), 10, 32)
+    if err != nil {
+        writeError(w, http.StatusNotFound, "Invalid note id")
+        return
+    }
+
+    s.mutex.Lock()
+    defer s.mutex.Unlock()
+    s.reload()
+
+    note := s.Notes.FindNote(uint(id))
+    if note == nil {
+        writeError(w, http.StatusNotFound, "Could not find note with id " + strconv.Itoa(int(id)))
+        return
+    }
+
+    switch(r.Method) {
+        case http.MethodGet:
+            writeJson(w, http.StatusOK, note)
+            break
+
+        case http.MethodPut:
+            fallthrough
+        case http.MethodPatch:
+            if !s.checkSaved(w) {
+                return
+            }
+
+            input, err := readNoteInput(w, r)
+            if err != nil {
+                writeError(w, http.StatusBadRequest, err.Error())
+                return
+            }
+
+            // Input is applied to a copy so that invalid input does not
+            // leave the note partially updated
+            updated := copyNote(note)
+            err = s.applyInput(&updated, input)
+            if err != nil {
+                writeError(w, http.StatusBadRequest, err.Error())
+                return
+            }
+            updated.Activity = note.Activity
+            *note = updated
+
+            s.scheduleSave()
+            writeJson(w, http.StatusOK, note)
+            break
+
+        case http.MethodDelete:
+            if !s.checkSaved(w) {
+                return
+            }
+
+            err = s.Notes.DeleteNote(uint(id))
+            if err != nil {
+                writeError(w, http.StatusInternalServerError, err.Error())
+                return
+            }
+            s.scheduleSave()
+            w.WriteHeader(http.StatusNoContent)
+            break
+
+        default:
+            w.Header().Set("Allow", "GET, PUT, PATCH, DELETE")
+            writeError(w, http.StatusMethodNotAllowed, "Method " + r.Method + " is not allowed")
+    }
+}
+
+// Handles listing tags with the number of notes having them
+func (s *NotesServer) handleTags(w http.ResponseWriter, r *http.Request) {
+    if r.Method != http.MethodGet {
+        w.Header().Set("Allow", "GET")
+        writeError(w, http.StatusMethodNotAllowed, "Method " + r.Method + " is not allowed")
+        return
+    }
+
+    s.mutex.Lock()
+    defer s.mutex.Unlock()
+    s.reload()
+
+    ret := []TagCount{}
+    for tag, count := range s.Notes.GetTags() {
+        ret = append(ret, TagCount{Tag: tag, Count: count})
+    }
+    sort.Slice(ret, func(i, j int) bool {
+        return ret[i].Tag < ret[j].Tag
+    })
+    writeJson(w, http.StatusOK, ret)
+}
+
+func (s *NotesServer) applyInput(note *Note, input *NoteInput) (error) {
+    if input.Content != nil {
+        note.Content = *input.Content
+    }
+
+    if input.Priority != nil {
+        if *input.Priority > 5 {
+            return errors.New("Invalid priority given. Priority should be in range 0-5")
+        }
+        note.Priority = *input.Priority
+    }
+
+    if input.Tags != nil {
+        note.Tags = nil
+        for _, tag := range *input.Tags {
+            if len(strings.Trim(tag, " ")) > 0 {
+                note.AddTag(tag)
+            }
+        }
+    }
+
+    if input.Due != nil {
+        note.Due = time.Time{}
+        if len(*input.Due) > 0 {
+            due, err := ParseDate(*input.Due, s.Config.DueFormat)
+            if err != nil {
+                return err
+            }
+            note.Due = due
+        }
+    }
+
+    if input.Estimate != nil {
+        note.Estimate = Estimate{}
+        if len(*input.Estimate) > 0 {
+            estimate, err := ParseEstimate(*input.Estimate)
+            if err != nil {
+                return err
+            }
+            note.Estimate = estimate
+        }
+    }
+
+    if input.Assignee != nil {
+        who, err := s.Notes.ResolveUser(*input.Assignee)
+        if err != nil {
+            return err
+        }
+        note.Assignee = who
+    }
+
+    if input.Status != nil {
+        err := s.Notes.SetStatus(note, *input.Status)
+        if err != nil {
+            return err
+        }
+    }
+
+    if input.Done != nil {
+        s.Notes.SetDone(note, *input.Done)
+    }
+    return nil
+}
+
+// Returns true if the notes can be modified. Otherwise writes an error as
+// saving the previous changes has failed.
+func (s *NotesServer) checkSaved(w http.ResponseWriter) (bool) {
+    if s.saveErr == nil {
+        return true
+    }
+
+    status := http.StatusServiceUnavailable
+    if s.saveErr == ErrNotesConflict {
+        status = http.StatusConflict
+    }
+    w.Header().Set("Retry-After", strconv.Itoa(int(s.SaveDelay.Seconds()) + 1))
+    writeError(w, status, "Saving previous changes failed: " + s.saveErr.Error())
+    return false
+}
+
+// Loads the notes again if they have been modified in Drive. Notes are not
+// reloaded when there are unsaved changes, they are being saved or they were
+// reloaded recently.
+func (s *NotesServer) reload() {
+    if s.unsavedModifications || s.savesInProgress > 0 || time.Since(s.lastReload) < s.SaveDelay {
+        return
+    }
+
+    s.lastReload = time.Now()
+    _, err := s.Notes.Reload()
+    if err != nil {
+        log.Printf("Reloading notes failed: %v", err)
+    }
+}
+
+// Saves the notes after the save delay. Changes made during the delay
+// postpone the save.
+func (s *NotesServer) scheduleSave() {
+    s.unsavedModifications = true
+    s.modifications++
+    s.startSaveTimer(s.SaveDelay)
+}
+
+func (s *NotesServer) startSaveTimer(delay time.Duration) {
+    if s.saveTimer != nil {
+        s.saveTimer.Reset(delay)
+        return
+    }
+    s.saveTimer = time.AfterFunc(delay, s.retrySave)
+}
+
+// Saves the notes retrying with increasing delay if saving fails. Changes
+// are rejected until the save succeeds so that nothing else is lost.
+func (s *NotesServer) retrySave() {
+    err := s.save()
+    if err == nil {
+        return
+    }
+
+    s.mutex.Lock()
+    defer s.mutex.Unlock()
+    delay := s.SaveDelay
+    for i := 1; i < s.saveFailures && delay < maxSaveBackoff; i++ {
+        delay *= 2
+    }
+    if delay > maxSaveBackoff {
+        delay = maxSaveBackoff
+    }
+    log.Printf("Saving notes failed %v times, retrying in %v: %v", s.saveFailures, delay, err)
+    s.startSaveTimer(delay)
+}
+
+// Saves the notes. Notes are uploaded without holding the mutex so that
+// requests are served during the upload. Notes stay unsaved until the upload
+// succeeds and changes made during it have been saved too.
+func (s *NotesServer) save() (error) {
+    s.mutex.Lock()
+    if !s.unsavedModifications {
+        s.mutex.Unlock()
+        return nil
+    }
+
+    ch := make(chan func() (error), 1)
+    s.Notes.AsyncSaveNotes(ch)
+    saved := s.modifications
+    s.savesInProgress++
+    s.mutex.Unlock()
+
+    finish := <-ch
+
+    s.mutex.Lock()
+    defer s.mutex.Unlock()
+    s.savesInProgress--
+    err := finish()
+    s.lastReload = time.Now()
+    if err != nil {
+        s.saveErr = err
+        s.saveFailures++
+        return err
+    }
+
+    if s.modifications == saved {
+        s.unsavedModifications = false
+    }
+    s.saveErr = nil
+    s.saveFailures = 0
+    return nil
+}
+
+func readNoteInput(w http.ResponseWriter, r *http.Request) (*NoteInput, error) {
+    input := &NoteInput{}
+    decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestSize))
+    decoder.DisallowUnknownFields()
+    err := decoder.Decode(input)
+    if err != nil {
+        return nil, errors.New("Invalid note given: " + err.Error())
+    }
+    return input, nil
+}
+
+func writeJson(w http.ResponseWriter, status int, v interface{}) {
+    w.Header().Set("Content-Type", "application/json")
+    w.WriteHeader(status)
+    err := json.NewEncoder(w).Encode(v)
+    if err != nil {
+        log.Printf("Writing response failed: %v", err)
+    }
+}
+
+func writeError(w http.ResponseWriter, status int, msg string) {
+    writeJson(w, status, map[string]string{"error": msg})
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+    "net/http"
+    "net/http/httptest"
+    "strings"
+    "testing"
+    "time"
+)
+
+func newTestServer() (*NotesServer, http.Handler) {
+    n := newTestNotes(
+        Note{Id: 1, Content: "Fix bug", Priority: 4, Tags: []string{"work"}},
+        Note{Id: 2, Content: "Buy milk", Priority: 2, Tags: []string{"home"}},
+    )
+
+    // Notes are not reloaded from Drive and saves are never run
+    s := &NotesServer{Notes: n, Config: n.config, Token: "secret", SaveDelay: time.Hour, lastReload: time.Now()}
+    mux := http.NewServeMux()
+    mux.HandleFunc("/notes", s.authorize(s.handleNotes))
+    mux.HandleFunc("/notes/", s.authorize(s.handleNote))
+    mux.HandleFunc("/tags", s.authorize(s.handleTags))
+    return s, mux
+}
+
+func TestServerHandlers(t *testing.T) {
+    s, handler := newTestServer()
+    defer func() {
+        if s.saveTimer != nil {
+            s.saveTimer.Stop()
+        }
+    }()
+
+    tests := []struct {
+        method string
+        path string
+        token string
+        body string
+        status int
+        contains string
+    }{
+        {"GET", "/notes", "", "", http.StatusUnauthorized, "Invalid or missing token"},
+        {"GET", "/notes", "wrong", "", http.StatusUnauthorized, "Invalid or missing token"},
+        {"GET", "/notes", "secret", "", http.StatusOK, `"content":"Buy milk"`},
+        {"GET", "/notes?q=tag:home", "secret", "", http.StatusOK, `"id":2`},
+        {"GET", "/notes?q=(tag:home", "secret", "", http.StatusBadRequest, "error"},
+        {"POST", "/notes", "secret", `{"content": "Write docs", "tags": ["work"], "priority": 3}`, http.StatusCreated, `"id":3`},
+        {"POST", "/notes", "secret", `{"content": " "}`, http.StatusBadRequest, "Note content is required"},
+        {"POST", "/notes", "secret", `{"content": "x", "unknown": 1}`, http.StatusBadRequest, "Invalid note given"},
+        {"POST", "/notes", "secret", `{"content": "x", "priority": 9}`, http.StatusBadRequest, "Invalid priority"},
+        {"POST", "/notes", "secret", `not json`, http.StatusBadRequest, "Invalid note given"},
+        {"PUT", "/notes", "secret", "", http.StatusMethodNotAllowed, "not allowed"},
+        {"GET", "/notes/1", "secret", "", http.StatusOK, `"content":"Fix bug"`},
+        {"GET", "/notes/99", "secret", "", http.StatusNotFound, "Could not find note"},
+        {"GET", "/notes/abc", "secret", "", http.StatusNotFound, "Invalid note id"},
+        {"PATCH", "/notes/1", "secret", `{"priority": 5, "done": true}`, http.StatusOK, `"priority":5`},
+        {"PATCH", "/notes/1", "secret", `{"due": "someday"}`, http.StatusBadRequest, "error"},
+        {"PATCH", "/notes/1", "secret", `{"assignee": "me"}`, http.StatusBadRequest, "Could not resolve current user"},
+        {"PATCH", "/notes/1", "secret", `{"assignee": "alice"}`, http.StatusOK, `"assignee":"alice"`},
+        {"GET", "/notes/1", "secret", "", http.StatusOK, `"done":true`},
+        {"DELETE", "/notes/2", "secret", "", http.StatusNoContent, ""},
+        {"GET", "/notes/2", "secret", "", http.StatusNotFound, "Could not find note"},
+        {"GET", "/tags", "secret", "", http.StatusOK, `"tag":"work"`},
+        {"POST", "/tags", "secret", "", http.StatusMethodNotAllowed, "not allowed"},
+    }
+
+    for _, test := range tests {
+        req := httptest.NewRequest(test.method, test.path, strings.NewReader(test.body))
+        if len(test.token) > 0 {
+            req.Header.Set("Authorization", "Bearer " + test.token)
+        }
+        rec := httptest.NewRecorder()
+        handler.ServeHTTP(rec, req)
+
+        if rec.Code != test.status {
+            t.Errorf("%v %v returned %v, want %v: %v", test.method, test.path, rec.Code, test.status, rec.Body.String())
+            continue
+        }
+        if !strings.Contains(rec.Body.String(), test.contains) {
+            t.Errorf("%v %v returned %q, want it to contain %q", test.method, test.path, rec.Body.String(), test.contains)
+        }
+    }
+
+    if !s.unsavedModifications {
+        t.Errorf("Modifications should be waiting to be saved")
+    }
+}
+
+func TestServerRejectsChangesWhenSaveFails(t *testing.T) {
+    tests := []struct {
+        err error
+        status int
+    }{
+        {ErrNotesConflict, http.StatusConflict},
+        {http.ErrHandlerTimeout, http.StatusServiceUnavailable},
+    }
+
+    for _, test := range tests {
+        s, handler := newTestServer()
+        s.saveErr = test.err
+
+        for _, method := range []string{"POST", "PATCH", "DELETE"} {
+            path := "/notes/1"
+            if method == "POST" {
+                path = "/notes"
+            }
+            req := httptest.NewRequest(method, path, strings.NewReader(`{"content": "x"}`))
+            req.Header.Set("Authorization", "Bearer secret")
+            rec := httptest.NewRecorder()
+            handler.ServeHTTP(rec, req)
+
+            if rec.Code != test.status {
+                t.Errorf("%v %v with save error %q returned %v, want %v", method, path, test.err, rec.Code, test.status)
+            }
+            if len(rec.Header().Get("Retry-After")) == 0 {
+                t.Errorf("%v %v should tell when to retry", method, path)
+            }
+        }
+
+        // Notes can still be read
+        req := httptest.NewRequest("GET", "/notes/1", nil)
+        req.Header.Set("Authorization", "Bearer secret")
+        rec := httptest.NewRecorder()
+        handler.ServeHTTP(rec, req)
+        if rec.Code != http.StatusOK {
+            t.Errorf("GET /notes/1 with save error returned %v, want %v", rec.Code, http.StatusOK)
+        }
+    }
+}
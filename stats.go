@@ -0,0 +1,144 @@
+package main
+
+import (
+    "sort"
+    "strconv"
+    "strings"
+    "time"
+)
+
+var sparkRunes = []rune("▁▂▃▄▅▆▇█")
+
+// Statistics of the notes
+type Stats struct {
+    Open int `json:"open"`
+    Done int `json:"done"`
+    Overdue int `json:"overdue"`
+    Weeks []WeekStats `json:"weeks"`
+    AverageCompletionHours float64 `json:"average_completion_hours"`
+    OverdueByPriority map[string]int `json:"overdue_by_priority"`
+    TopTags []TagCount `json:"top_tags"`
+}
+
+// Number of notes created and completed during a week starting on monday
+type WeekStats struct {
+    Start string `json:"start"`
+    Created int `json:"created"`
+    Completed int `json:"completed"`
+}
+
+type TagCount struct {
+    Tag string `json:"tag"`
+    Count int `json:"count"`
+}
+
+// Returns monday of the week of given time
+func GetWeekStart(t time.Time) (time.Time) {
+    day := RoundTimeToDay(t)
+    return day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+}
+
+// Calculates statistics of the notes for the given number of latest weeks
+func (n *Notes) GetStats(notes []*Note, weeks int, topTags int) (Stats) {
+    ret := Stats{OverdueByPriority: map[string]int{}}
+    today := GetToday()
+
+    first := GetWeekStart(time.Now()).AddDate(0, 0, -7 * (weeks - 1))
+    for i := 0; i < weeks; i++ {
+        ret.Weeks = append(ret.Weeks, WeekStats{Start: first.AddDate(0, 0, 7 * i).Format("2006-01-02")})
+    }
+
+    var completionTime time.Duration
+    completed := 0
+    tags := map[string]int{}
+    for _, note := range notes {
+        for _, tag := range note.Tags {
+            tags[n.config.CanonicalTag(tag)]++
+        }
+
+        if !note.Created.IsZero() && !note.Created.Before(first) {
+            idx := int(note.Created.Sub(first).Hours()) / (24 * 7)
+            if idx < weeks {
+                ret.Weeks[idx].Created++
+            }
+        }
+
+        if note.Done {
+            ret.Done++
+            completedAt := n.GetCompletedTime(note)
+            if completedAt.IsZero() {
+                continue
+            }
+
+            if !completedAt.Before(first) {
+                idx := int(completedAt.Sub(first).Hours()) / (24 * 7)
+                if idx < weeks {
+                    ret.Weeks[idx].Completed++
+                }
+            }
+
+            if !note.Created.IsZero() && completedAt.After(note.Created) {
+                completionTime += completedAt.Sub(note.Created)
+                completed++
+            }
+            continue
+        }
+
+        ret.Open++
+        if !note.Due.IsZero() && RoundTimeToDay(note.Due).Before(today) {
+            ret.Overdue++
+            ret.OverdueByPriority[strconv.Itoa(int(note.Priority))]++
+        }
+    }
+
+    if completed > 0 {
+        ret.AverageCompletionHours = (completionTime / time.Duration(completed)).Hours()
+    }
+
+    for tag, count := range tags {
+        ret.TopTags = append(ret.TopTags, TagCount{Tag: tag, Count: count})
+    }
+    sort.Slice(ret.TopTags, func(i, j int) bool {
+        if ret.TopTags[i].Count == ret.TopTags[j].Count {
+            return ret.TopTags[i].Tag < ret.TopTags[j].Tag
+        }
+        return ret.TopTags[i].Count > ret.TopTags[j].Count
+    })
+    if len(ret.TopTags) > topTags {
+        ret.TopTags = ret.TopTags[:topTags]
+    }
+    return ret
+}
+
+// Returns the values as a sparkline such as "▁▂▅█▃"
+func Sparkline(values []int) (string) {
+    max := 0
+    for _, value := range values {
+        if value > max {
+            max = value
+        }
+    }
+
+    ret := ""
+    for _, value := range values {
+        idx := 0
+        if max > 0 {
+            idx = value * (len(sparkRunes) - 1) / max
+        }
+        ret += string(sparkRunes[idx])
+    }
+    return ret
+}
+
+// Returns bar with length relative to the maximum value
+func Bar(value int, max int, width int) (string) {
+    if max <= 0 || value <= 0 {
+        return ""
+    }
+
+    length := value * width / max
+    if length == 0 {
+        length = 1
+    }
+    return strings.Repeat("█", length)
+}
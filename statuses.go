@@ -0,0 +1,131 @@
+package main
+
+import (
+    "errors"
+    "strings"
+    "time"
+)
+
+// Returns the workflow status of the note. Notes saved before statuses were
+// introduced get their status from the done flag. Missing statuses are not
+// stored in the notes as the statuses depend on the configuration of each
+// user of a shared notebook.
+func (n *Notes) GetStatus(note *Note) (string) {
+    if len(note.Status) > 0 {
+        return note.Status
+    }
+
+    if note.Done {
+        return n.config.GetDoneStatus()
+    }
+    return n.config.GetOpenStatus()
+}
+
+// Sets the status of the note. Done is updated to match the status.
+func (n *Notes) SetStatus(note *Note, status string) (error) {
+    found, ok := n.config.FindStatus(status)
+    if !ok {
+        return errors.New("Invalid status " + status + ", use one of: " + strings.Join(n.config.Statuses, ", "))
+    }
+
+    note.Status = found
+    setDoneFlag(note, n.config.IsDoneStatus(found))
+    return nil
+}
+
+// Marks the note done or not done. Status is changed only if it does not
+// already match so that for example cancelled notes stay cancelled.
+func (n *Notes) SetDone(note *Note, done bool) {
+    if n.isDone(note) != done {
+        if done {
+            note.Status = n.config.GetDoneStatus()
+        } else {
+            note.Status = n.config.GetOpenStatus()
+        }
+    }
+    setDoneFlag(note, done)
+}
+
+// Sets done flag of the note recording the time it was completed
+func setDoneFlag(note *Note, done bool) {
+    if done && !note.Done {
+        note.CompletedAt = time.Now()
+    } else if !done {
+        note.CompletedAt = time.Time{}
+    }
+    note.Done = done
+}
+
+// Returns the time the note was completed or zero time if it is not done.
+// Notes completed before the completion time was recorded get it from the
+// activity log. Zero time is returned if the time is not known.
+func (n *Notes) GetCompletedTime(note *Note) (time.Time) {
+    if !note.Done {
+        return time.Time{}
+    }
+
+    if !note.CompletedAt.IsZero() {
+        return note.CompletedAt
+    }
+
+    for i := len(note.Activity) - 1; i >= 0; i-- {
+        action := note.Activity[i].Action
+        if action == "marked done" {
+            return note.Activity[i].Time
+        }
+
+        if strings.HasPrefix(action, "changed status from ") {
+            parts := strings.SplitN(action, " to ", 2)
+            if len(parts) == 2 && n.config.IsDoneStatus(parts[1]) {
+                return note.Activity[i].Time
+            }
+        }
+    }
+
+    return time.Time{}
+}
+
+// Returns true if the status of the note is a done status. Statuses missing
+// from the configuration, for example ones set by other users of a shared
+// notebook, are kept as they are and the done flag tells if they are done.
+func (n *Notes) isDone(note *Note) (bool) {
+    status := n.GetStatus(note)
+    if !containsString(n.config.Statuses, status) {
+        return note.Done
+    }
+    return n.config.IsDoneStatus(status)
+}
+
+// Returns the status following the current status of the note
+func (n *Notes) GetNextStatus(note *Note) (string) {
+    current := n.GetStatus(note)
+    for i, status := range n.config.Statuses {
+        if status == current {
+            return n.config.Statuses[(i + 1) % len(n.config.Statuses)]
+        }
+    }
+    return n.config.Statuses[0]
+}
+
+// Returns index of the note status in the workflow. Unknown statuses are
+// ordered last.
+func (n *Notes) getStatusIndex(note *Note) (int) {
+    status := n.GetStatus(note)
+    for i, s := range n.config.Statuses {
+        if s == status {
+            return i
+        }
+    }
+    return len(n.config.Statuses)
+}
+
+func (n *Notes) FilterNotesByStatus(status string, notes []*Note) ([]*Note) {
+    var ret []*Note
+    status = normalizeStatus(status)
+    for _, note := range notes {
+        if normalizeStatus(n.GetStatus(note)) == status {
+            ret = append(ret, note)
+        }
+    }
+    return ret
+}
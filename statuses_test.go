@@ -0,0 +1,94 @@
+package main
+
+import (
+    "encoding/json"
+    "testing"
+    "time"
+)
+
+func TestGetCompletedTime(t *testing.T) {
+    n := newTestNotes()
+    created := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+    updated := created.Add(48 * time.Hour)
+    completed := created.Add(24 * time.Hour)
+
+    tests := []struct {
+        name string
+        note Note
+        expected time.Time
+    }{
+        {"open", Note{Created: created, CompletedAt: completed}, time.Time{}},
+        {"recorded", Note{Done: true, Created: created, Updated: updated, CompletedAt: completed}, completed},
+        {"marked done", Note{Done: true, Created: created, Updated: updated, Activity: []Activity{
+            {Time: created, Action: "created"},
+            {Time: completed, Action: "marked done"},
+            {Time: updated, Action: "added comment"},
+        }}, completed},
+        {"status changed", Note{Done: true, Created: created, Updated: updated, Activity: []Activity{
+            {Time: created, Action: "changed status from open to in progress"},
+            {Time: completed, Action: "changed status from in progress to done"},
+        }}, completed},
+        {"unknown", Note{Done: true, Created: created, Updated: updated}, time.Time{}},
+    }
+
+    for _, test := range tests {
+        completedAt := n.GetCompletedTime(&test.note)
+        if !completedAt.Equal(test.expected) {
+            t.Errorf("%v: GetCompletedTime() = %v, want %v", test.name, completedAt, test.expected)
+        }
+    }
+}
+
+func TestSetDone(t *testing.T) {
+    n := newTestNotes()
+
+    tests := []struct {
+        name string
+        note Note
+        done bool
+        status string
+    }{
+        {"open", Note{}, true, "done"},
+        {"done", Note{Done: true}, false, "todo"},
+        {"in progress", Note{Status: "in progress"}, true, "done"},
+        {"already done", Note{Status: "done", Done: true}, true, "done"},
+        {"unknown open", Note{Status: "qa"}, false, "qa"},
+        {"unknown done", Note{Status: "shipped", Done: true}, true, "shipped"},
+        {"unknown marked done", Note{Status: "qa"}, true, "done"},
+        {"unknown marked not done", Note{Status: "shipped", Done: true}, false, "todo"},
+    }
+
+    for _, test := range tests {
+        note := test.note
+        n.SetDone(&note, test.done)
+        if note.Done != test.done || n.GetStatus(&note) != test.status {
+            t.Errorf("%v: SetDone(%v) gave status %q and done %v, want %q", test.name, test.done, n.GetStatus(&note), note.Done, test.status)
+        }
+    }
+}
+
+func TestMissingStatusIsNotStored(t *testing.T) {
+    n := newTestNotes(Note{Id: 1}, Note{Id: 2, Done: true}, Note{Id: 3, Status: "qa"})
+
+    expected := []string{"todo", "done", "qa"}
+    for i, note := range n.GetNotes() {
+        if n.GetStatus(note) != expected[i] {
+            t.Errorf("GetStatus() of note %v = %q, want %q", note.Id, n.GetStatus(note), expected[i])
+        }
+    }
+
+    // Other users of a shared notebook can have different statuses
+    data, err := json.Marshal(n.notes)
+    if err != nil {
+        t.Fatal(err)
+    }
+    notes, err := n.decodeNotes(data)
+    if err != nil {
+        t.Fatal(err)
+    }
+    for i, status := range []string{"", "", "qa"} {
+        if notes[i].Status != status {
+            t.Errorf("Status of note %v = %q, want %q", notes[i].Id, notes[i].Status, status)
+        }
+    }
+}
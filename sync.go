@@ -0,0 +1,534 @@
+package main
+
+import (
+    "bytes"
+    "encoding/json"
+    "io/ioutil"
+    "os"
+    "reflect"
+    "sort"
+
+    "google.golang.org/api/drive/v3"
+)
+
+// Number of times changes made by others are merged before giving up saving
+const MAX_SAVE_ATTEMPTS = 3
+
+// Notes prepared for saving. Saves are prepared in the goroutine editing the
+// notes and uploaded in the background so they must not share anything with
+// the edited notes.
+type notesSave struct {
+    seq uint64
+    version uint64
+    base map[uint]Note
+    notes []Note
+    removedFiles []string
+}
+
+// Result of uploading prepared notes. File is nil if nothing was uploaded.
+type savedNotes struct {
+    seq uint64
+    version uint64
+    file *drive.File
+    notes []Note
+    merged bool
+    conflicts []uint
+    removedFiles []string
+    err error
+}
+
+// Saves the notes in the background. The notes are copied before returning
+// so they can be edited during the save. The function sent to the channel
+// applies the changes merged from Drive and returns the result of the save.
+// It has to be called from the goroutine editing the notes.
+func (n *Notes) AsyncSaveNotes(ch chan<- func() (error)) {
+    save := n.prepareSave()
+    go func() {
+        result := n.uploadNotes(save)
+        ch <- func() (error) {
+            return n.finishSave(save, result)
+        }
+    }()
+}
+
+func (n *Notes) SaveNotes() (error) {
+    save := n.prepareSave()
+    return n.finishSave(save, n.uploadNotes(save))
+}
+
+func (n *Notes) prepareSave() (*notesSave) {
+    n.recordActivity()
+    n.saveSeq++
+    save := &notesSave{
+        seq: n.saveSeq,
+        version: n.syncedVersion,
+        base: n.synced,
+        notes: cloneNotes(n.notes),
+        removedFiles: n.removedFiles,
+    }
+    n.removedFiles = nil
+    return save
+}
+
+// Uploads the prepared notes. Saves are serialized so that only one of them
+// talks to Drive at a time. Changes made by others since the notes were
+// loaded are merged before uploading. Drive v3 does not support conditional
+// updates so the head revision is compared just before uploading.
+func (n *Notes) uploadNotes(save *notesSave) (*savedNotes) {
+    n.saveMutex.Lock()
+    defer n.saveMutex.Unlock()
+
+    // Files are removed again with the next save if this one fails
+    ret := &savedNotes{seq: save.seq, removedFiles: save.removedFiles}
+    if save.seq < n.uploadedSeq {
+        // Newer save has already uploaded the changes of this one
+        ret.removedFiles = n.deleteFiles(save.removedFiles)
+        return ret
+    }
+
+    notes := save.notes
+    base := save.base
+    if save.version != n.version {
+        // Notes have been saved or reloaded after the save was prepared
+        var conflicts []uint
+        notes, conflicts = mergeNotes(base, notes, n.remoteNotes)
+        base = notesById(n.remoteNotes)
+        ret.merged = true
+        ret.conflicts = append(ret.conflicts, conflicts...)
+    }
+
+    for attempt := 1; ; attempt++ {
+        // Private notebooks are only modified by the current user
+        if n.config.IsShared() && len(n.file.HeadRevisionId) > 0 {
+            remote, err := n.getRemoteFile()
+            if err != nil {
+                ret.err = err
+                return ret
+            }
+
+            if remote.HeadRevisionId != n.file.HeadRevisionId {
+                if attempt > MAX_SAVE_ATTEMPTS {
+                    ret.err = ErrNotesConflict
+                    return ret
+                }
+
+                remoteNotes, err := n.downloadNotes(remote)
+                if err != nil {
+                    ret.err = err
+                    return ret
+                }
+                var conflicts []uint
+                notes, conflicts = mergeNotes(base, notes, remoteNotes)
+                base = notesById(remoteNotes)
+                n.setRemote(remote, remoteNotes)
+                ret.merged = true
+                ret.conflicts = append(ret.conflicts, conflicts...)
+                continue
+            }
+        }
+
+        file, err := n.uploadFile(notes)
+        if err != nil {
+            ret.err = err
+            return ret
+        }
+        n.setRemote(file, notes)
+        n.uploadedSeq = save.seq
+
+        ret.file = file
+        ret.version = n.version
+        ret.notes = cloneNotes(notes)
+
+        // Attachment files are removed only after the notes referring to
+        // them have been saved
+        ret.removedFiles = n.deleteFiles(save.removedFiles)
+        return ret
+    }
+}
+
+// Applies the result of the save to the notes. Notes modified after the save
+// was prepared are kept as they are. ErrNotesConflict is returned if changes
+// conflicted with the changes of others and were replaced by them.
+func (n *Notes) finishSave(save *notesSave, result *savedNotes) (error) {
+    n.removedFiles = append(n.removedFiles, result.removedFiles...)
+    if result.seq <= n.appliedSeq {
+        // Newer save has already been applied
+        return nil
+    }
+    if result.file == nil {
+        return result.err
+    }
+    n.appliedSeq = result.seq
+
+    if result.merged {
+        prepared := notesById(save.notes)
+        modified := map[uint]bool{}
+        for i, _ := range n.notes {
+            old, ok := prepared[n.notes[i].Id]
+            modified[n.notes[i].Id] = !ok || !sameNote(&old, &n.notes[i]) || !notesEqual(&old, &n.notes[i])
+        }
+
+        n.notes, _ = mergeNotes(prepared, n.notes, cloneNotes(result.notes))
+
+        // Changes made by others are not recorded as activity of the
+        // current user
+        snapshot := map[uint]Note{}
+        for i, _ := range n.notes {
+            note := &n.notes[i]
+            old, ok := n.snapshot[note.Id]
+            if !modified[note.Id] {
+                snapshot[note.Id] = copyNote(note)
+            } else if ok {
+                snapshot[note.Id] = old
+            }
+        }
+        n.snapshot = snapshot
+    }
+
+    n.synced = notesById(result.notes)
+    n.syncedVersion = result.version
+    n.config.Md5Checksum = result.file.Md5Checksum
+    n.config.Save()
+    if result.err == nil && len(result.conflicts) > 0 {
+        return ErrNotesConflict
+    }
+    return result.err
+}
+
+// Remembers the notes stored in Drive. Must be called with the save mutex
+// locked.
+func (n *Notes) setRemote(file *drive.File, notes []Note) {
+    n.file = file
+    n.remoteNotes = notes
+    n.version++
+}
+
+func (n *Notes) getRemoteFile() (*drive.File, error) {
+    get := n.gdrive.Files.Get(n.file.Id).Fields(notesFileFields)
+    if n.config.IsShared() {
+        get.SupportsAllDrives(true)
+    }
+    return get.Do()
+}
+
+func (n *Notes) uploadFile(notes []Note) (*drive.File, error) {
+    data, err := json.Marshal(notes)
+    if err != nil {
+        return nil, err
+    }
+
+    update := n.gdrive.Files.Update(n.file.Id, &drive.File{})
+    update.Fields(notesFileFields)
+    if n.config.IsShared() {
+        update.SupportsAllDrives(true)
+    }
+    file, err := update.Media(bytes.NewReader(data)).Do()
+    if err != nil {
+        return nil, err
+    }
+
+    // Uploaded notes are used as the cache of the notes file
+    n.writeCache(file, data)
+    return file, nil
+}
+
+// Downloads the notes file from Drive
+func (n *Notes) downloadNotes(file *drive.File) ([]Note, error) {
+    export := n.gdrive.Files.Get(file.Id)
+    if n.config.IsShared() {
+        export.SupportsAllDrives(true)
+    }
+
+    res, err := export.Download()
+    if err != nil {
+        return nil, err
+    }
+    defer res.Body.Close()
+
+    data, err := ioutil.ReadAll(res.Body)
+    if err != nil {
+        return nil, err
+    }
+
+    notes, err := n.decodeNotes(data)
+    if err != nil {
+        return nil, err
+    }
+    n.writeCache(file, data)
+    return notes, nil
+}
+
+// Returns the notes from the cached notes file if it matches the given file
+func (n *Notes) readCache(file *drive.File) ([]Note, bool) {
+    if file.Md5Checksum != n.config.Md5Checksum {
+        return nil, false
+    }
+
+    data, err := ioutil.ReadFile(os.TempDir() + "/" + file.Name)
+    if err != nil {
+        return nil, false
+    }
+
+    notes, err := n.decodeNotes(data)
+    if err != nil {
+        return nil, false
+    }
+    return notes, true
+}
+
+func (n *Notes) writeCache(file *drive.File, data []byte) {
+    ioutil.WriteFile(os.TempDir() + "/" + file.Name, data, 0600)
+}
+
+func (n *Notes) decodeNotes(data []byte) ([]Note, error) {
+    notes := make([]Note, 0)
+    if len(data) == 0 {
+        return notes, nil
+    }
+
+    err := json.Unmarshal(data, &notes)
+    if err != nil {
+        return nil, err
+    }
+    return notes, nil
+}
+
+// Returns copies of the notes that do not share anything with the originals
+func cloneNotes(notes []Note) ([]Note) {
+    ret := make([]Note, len(notes))
+    for i, _ := range notes {
+        ret[i] = copyNote(&notes[i])
+        ret[i].Activity = append([]Activity(nil), notes[i].Activity...)
+    }
+    return ret
+}
+
+func notesById(notes []Note) (map[uint]Note) {
+    ret := map[uint]Note{}
+    for _, note := range notes {
+        ret[note.Id] = note
+    }
+    return ret
+}
+
+// Returns true if the notes are the same note. Ids of deleted notes can be
+// reused so the creation time is compared as well.
+func sameNote(a *Note, b *Note) (bool) {
+    return a.Id == b.Id && a.Created.Equal(b.Created)
+}
+
+// Returns true if the notes have the same content. Activity is not compared
+// as it is only appended when the other fields change.
+func notesEqual(a *Note, b *Note) (bool) {
+    aCopy := copyNote(a)
+    bCopy := copyNote(b)
+    aJson, aErr := json.Marshal(aCopy)
+    bJson, bErr := json.Marshal(bCopy)
+    return aErr == nil && bErr == nil && bytes.Equal(aJson, bJson)
+}
+
+// Merges notes modified locally and by others since base. Notes modified on
+// both sides are merged field by field. Fields changed differently on both
+// sides keep the changes of others and the ids of such notes are returned.
+// Notes deleted on either side are removed unless they were modified on the
+// other side. New local notes whose ids have been taken by notes created by
+// others get new ids.
+func mergeNotes(base map[uint]Note, local []Note, remote []Note) ([]Note, []uint) {
+    remoteById := notesById(remote)
+    var ret []Note
+    var renumbered []int
+    var conflicts []uint
+    fromLocal := map[int]bool{}
+    taken := map[uint]bool{}
+
+    for _, note := range local {
+        old, inBase := base[note.Id]
+        inBase = inBase && sameNote(&old, &note)
+        modified := !inBase || !notesEqual(&old, &note)
+        other, inRemote := remoteById[note.Id]
+
+        if inRemote && sameNote(&other, &note) {
+            taken[note.Id] = true
+            if !modified {
+                ret = append(ret, other)
+                continue
+            }
+
+            if inBase && !notesEqual(&old, &other) {
+                merged, ok := mergeNote(&old, &note, &other)
+                if !ok {
+                    conflicts = append(conflicts, note.Id)
+                }
+                note = merged
+            }
+            ret = append(ret, note)
+            fromLocal[len(ret) - 1] = true
+            continue
+        }
+
+        // Unmodified notes deleted by others are removed
+        if !modified {
+            continue
+        }
+
+        ret = append(ret, note)
+        fromLocal[len(ret) - 1] = true
+
+        // Id is kept if the note of others using it was deleted locally
+        removed := base[note.Id]
+        if inRemote && !sameNote(&removed, &other) {
+            renumbered = append(renumbered, len(ret) - 1)
+        }
+    }
+
+    for _, note := range remote {
+        if taken[note.Id] {
+            continue
+        }
+
+        // Notes deleted locally stay deleted unless modified by others
+        old, inBase := base[note.Id]
+        if inBase && sameNote(&old, &note) && notesEqual(&old, &note) {
+            continue
+        }
+        ret = append(ret, note)
+    }
+
+    var maxId uint
+    for _, note := range ret {
+        if note.Id > maxId {
+            maxId = note.Id
+        }
+    }
+
+    ids := map[uint]uint{}
+    for _, i := range renumbered {
+        maxId++
+        ids[ret[i].Id] = maxId
+        ret[i].Id = maxId
+    }
+
+    // References between local notes follow the renumbered notes
+    for i, _ := range ret {
+        if !fromLocal[i] || len(ids) == 0 {
+            continue
+        }
+        note := &ret[i]
+        if id, ok := ids[note.Parent]; ok {
+            note.Parent = id
+        }
+        for j, dep := range note.DependsOn {
+            if id, ok := ids[dep]; ok {
+                note.DependsOn[j] = id
+            }
+        }
+    }
+
+    sort.SliceStable(ret, func(i, j int) bool {
+        return ret[i].Id < ret[j].Id
+    })
+    return ret, conflicts
+}
+
+// Fields of a note that are merged together as they are changed together
+var noteFieldGroups = [][]string{
+    {"Done", "Status", "CompletedAt"},
+}
+
+// Merges a note modified locally and by others field by field. Returns false
+// if a field was changed differently on both sides, in which case the change
+// of others is kept.
+func mergeNote(base *Note, local *Note, remote *Note) (Note, bool) {
+    ret := *remote
+    ok := true
+
+    grouped := map[string]bool{}
+    groups := [][]string{}
+    for _, group := range noteFieldGroups {
+        for _, name := range group {
+            grouped[name] = true
+        }
+        groups = append(groups, group)
+    }
+    t := reflect.TypeOf(ret)
+    for i := 0; i < t.NumField(); i++ {
+        if !grouped[t.Field(i).Name] {
+            groups = append(groups, []string{t.Field(i).Name})
+        }
+    }
+
+    b := reflect.ValueOf(base).Elem()
+    l := reflect.ValueOf(local).Elem()
+    r := reflect.ValueOf(remote).Elem()
+    m := reflect.ValueOf(&ret).Elem()
+    for _, group := range groups {
+        if fieldsEqual(l, b, group) || fieldsEqual(l, r, group) {
+            continue
+        }
+        if fieldsEqual(r, b, group) {
+            for _, name := range group {
+                m.FieldByName(name).Set(l.FieldByName(name))
+            }
+            continue
+        }
+
+        // Changed on both sides
+        switch(group[0]) {
+            case "Updated":
+                if local.Updated.After(remote.Updated) {
+                    ret.Updated = local.Updated
+                }
+                continue
+            case "Activity":
+                fallthrough
+            case "Comments":
+                merged, appended := mergeAppended(b.FieldByName(group[0]), l.FieldByName(group[0]), r.FieldByName(group[0]))
+                if appended {
+                    m.FieldByName(group[0]).Set(merged)
+                    continue
+                }
+                break
+        }
+        ok = false
+    }
+    return ret, ok
+}
+
+// Returns true if the named fields of the notes have the same values
+func fieldsEqual(a reflect.Value, b reflect.Value, names []string) (bool) {
+    for _, name := range names {
+        aJson, aErr := json.Marshal(a.FieldByName(name).Interface())
+        bJson, bErr := json.Marshal(b.FieldByName(name).Interface())
+        if aErr != nil || bErr != nil || !bytes.Equal(aJson, bJson) {
+            return false
+        }
+    }
+    return true
+}
+
+// Merges lists that are only appended to, such as comments, by adding the
+// local additions after the ones of others. Returns false if the lists have
+// been modified otherwise.
+func mergeAppended(base reflect.Value, local reflect.Value, remote reflect.Value) (reflect.Value, bool) {
+    if !isPrefix(base, local) || !isPrefix(base, remote) {
+        return remote, false
+    }
+
+    ret := reflect.MakeSlice(remote.Type(), 0, remote.Len() + local.Len() - base.Len())
+    ret = reflect.AppendSlice(ret, remote)
+    return reflect.AppendSlice(ret, local.Slice(base.Len(), local.Len())), true
+}
+
+func isPrefix(prefix reflect.Value, list reflect.Value) (bool) {
+    if prefix.Len() > list.Len() {
+        return false
+    }
+    for i := 0; i < prefix.Len(); i++ {
+        aJson, aErr := json.Marshal(prefix.Index(i).Interface())
+        bJson, bErr := json.Marshal(list.Index(i).Interface())
+        if aErr != nil || bErr != nil || !bytes.Equal(aJson, bJson) {
+            return false
+        }
+    }
+    return true
+}
@@ -0,0 +1,216 @@
+package main
+
+import (
+    "reflect"
+    "strconv"
+    "testing"
+    "time"
+)
+
+// Returns notes as id:content strings with the parent of sub notes
+func describeNotes(notes []Note) ([]string) {
+    ret := []string{}
+    for _, note := range notes {
+        str := strconv.Itoa(int(note.Id)) + ":" + note.Content
+        if note.Parent > 0 {
+            str += "^" + strconv.Itoa(int(note.Parent))
+        }
+        ret = append(ret, str)
+    }
+    return ret
+}
+
+func TestMergeNotes(t *testing.T) {
+    created := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+    later := created.Add(time.Hour)
+    note := func(id uint, content string) (Note) {
+        return Note{Id: id, Content: content, Created: created}
+    }
+
+    tests := []struct {
+        name string
+        base []Note
+        local []Note
+        remote []Note
+        expected []string
+        conflicts []uint
+    }{
+        {
+            "modified locally",
+            []Note{note(1, "a"), note(2, "b")},
+            []Note{note(1, "a2"), note(2, "b")},
+            []Note{note(1, "a"), note(2, "b")},
+            []string{"1:a2", "2:b"},
+            nil,
+        },
+        {
+            "modified by others",
+            []Note{note(1, "a"), note(2, "b")},
+            []Note{note(1, "a2"), note(2, "b")},
+            []Note{note(1, "a"), note(2, "b2")},
+            []string{"1:a2", "2:b2"},
+            nil,
+        },
+        {
+            "same field modified on both sides",
+            []Note{note(1, "a")},
+            []Note{note(1, "local")},
+            []Note{note(1, "remote")},
+            []string{"1:remote"},
+            []uint{1},
+        },
+        {
+            "same change on both sides",
+            []Note{note(1, "a")},
+            []Note{note(1, "b")},
+            []Note{note(1, "b")},
+            []string{"1:b"},
+            nil,
+        },
+        {
+            "deleted locally",
+            []Note{note(1, "a"), note(2, "b")},
+            []Note{note(1, "a")},
+            []Note{note(1, "a"), note(2, "b")},
+            []string{"1:a"},
+            nil,
+        },
+        {
+            "deleted locally and modified by others",
+            []Note{note(1, "a"), note(2, "b")},
+            []Note{note(1, "a")},
+            []Note{note(1, "a"), note(2, "b2")},
+            []string{"1:a", "2:b2"},
+            nil,
+        },
+        {
+            "deleted by others",
+            []Note{note(1, "a"), note(2, "b")},
+            []Note{note(1, "a"), note(2, "b")},
+            []Note{note(1, "a")},
+            []string{"1:a"},
+            nil,
+        },
+        {
+            "modified locally and deleted by others",
+            []Note{note(1, "a"), note(2, "b")},
+            []Note{note(1, "a"), note(2, "b2")},
+            []Note{note(1, "a")},
+            []string{"1:a", "2:b2"},
+            nil,
+        },
+        {
+            "created on both sides",
+            []Note{note(1, "a")},
+            []Note{note(1, "a"), note(2, "local"), Note{Id: 3, Content: "sub", Created: created, Parent: 2}},
+            []Note{note(1, "a"), Note{Id: 2, Content: "remote", Created: later}},
+            []string{"1:a", "2:remote", "3:sub^4", "4:local"},
+            nil,
+        },
+        {
+            "created by others",
+            []Note{note(1, "a")},
+            []Note{note(1, "a")},
+            []Note{note(1, "a"), note(2, "b")},
+            []string{"1:a", "2:b"},
+            nil,
+        },
+        {
+            "id reused after delete",
+            []Note{note(1, "a"), note(2, "b")},
+            []Note{note(1, "a"), Note{Id: 2, Content: "new", Created: later}},
+            []Note{note(1, "a"), note(2, "b")},
+            []string{"1:a", "2:new"},
+            nil,
+        },
+        {
+            "own save",
+            []Note{note(1, "a")},
+            []Note{note(1, "a2"), note(2, "b")},
+            []Note{note(1, "a2"), note(2, "b")},
+            []string{"1:a2", "2:b"},
+            nil,
+        },
+    }
+
+    for _, test := range tests {
+        merged, conflicts := mergeNotes(notesById(test.base), test.local, test.remote)
+        if !reflect.DeepEqual(describeNotes(merged), test.expected) {
+            t.Errorf("%v: mergeNotes() = %v, want %v", test.name, describeNotes(merged), test.expected)
+        }
+        if !reflect.DeepEqual(conflicts, test.conflicts) {
+            t.Errorf("%v: mergeNotes() conflicts = %v, want %v", test.name, conflicts, test.conflicts)
+        }
+    }
+}
+
+func TestMergeNoteFields(t *testing.T) {
+    created := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+    later := created.Add(time.Hour)
+    completed := created.Add(2 * time.Hour)
+    comment := func(text string) (Comment) {
+        return Comment{Author: "alice", Created: created, Text: text}
+    }
+    base := Note{Id: 1, Content: "a", Priority: 2, Status: "todo", Created: created, Updated: created, Tags: []string{"work"}, Comments: []Comment{comment("first")}}
+
+    tests := []struct {
+        name string
+        local func(note *Note)
+        remote func(note *Note)
+        expected func(note *Note)
+        ok bool
+    }{
+        {
+            "different fields",
+            func(note *Note) { note.Content = "local"; note.Updated = later },
+            func(note *Note) { note.Priority = 5; note.Updated = created.Add(time.Minute) },
+            func(note *Note) { note.Content = "local"; note.Priority = 5; note.Updated = later },
+            true,
+        },
+        {
+            "same field",
+            func(note *Note) { note.Priority = 4 },
+            func(note *Note) { note.Priority = 5 },
+            func(note *Note) { note.Priority = 5 },
+            false,
+        },
+        {
+            "comments added on both sides",
+            func(note *Note) { note.Comments = append(note.Comments, comment("local")) },
+            func(note *Note) { note.Comments = append(note.Comments, comment("remote")) },
+            func(note *Note) { note.Comments = append(note.Comments, comment("remote"), comment("local")) },
+            true,
+        },
+        {
+            "comment removed by others",
+            func(note *Note) { note.Comments = append(note.Comments, comment("local")) },
+            func(note *Note) { note.Comments = nil },
+            func(note *Note) { note.Comments = nil },
+            false,
+        },
+        {
+            "marked done while status changed by others",
+            func(note *Note) { note.Done = true; note.Status = "done"; note.CompletedAt = completed },
+            func(note *Note) { note.Status = "in progress"; note.Tags = []string{"home"} },
+            func(note *Note) { note.Status = "in progress"; note.Tags = []string{"home"} },
+            false,
+        },
+    }
+
+    for _, test := range tests {
+        local := cloneNotes([]Note{base})[0]
+        remote := cloneNotes([]Note{base})[0]
+        expected := cloneNotes([]Note{base})[0]
+        test.local(&local)
+        test.remote(&remote)
+        test.expected(&expected)
+
+        merged, ok := mergeNote(&base, &local, &remote)
+        if ok != test.ok {
+            t.Errorf("%v: mergeNote() ok = %v, want %v", test.name, ok, test.ok)
+        }
+        if !notesEqual(&merged, &expected) {
+            t.Errorf("%v: mergeNote() = %+v, want %+v", test.name, merged, expected)
+        }
+    }
+}
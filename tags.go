@@ -0,0 +1,191 @@
+package main
+
+import (
+    "errors"
+    "sort"
+    "strings"
+)
+
+// Separator of the tag hierarchy, for example "work/backend"
+const TAG_SEPARATOR = "/"
+
+// Returns true if the tag is the filter tag or under it in the hierarchy
+func TagMatches(tag string, filter string) (bool) {
+    return tag == filter || strings.HasPrefix(tag, filter + TAG_SEPARATOR)
+}
+
+// Returns parent of the tag in the hierarchy or empty string for top level tags
+func GetParentTag(tag string) (string) {
+    idx := strings.LastIndex(tag, TAG_SEPARATOR)
+    if idx == -1 {
+        return ""
+    }
+    return tag[:idx]
+}
+
+// Returns the tag and all of its parents in the hierarchy
+func GetTagWithParents(tag string) ([]string) {
+    var ret []string
+    for len(tag) > 0 {
+        ret = append(ret, tag)
+        tag = GetParentTag(tag)
+    }
+    return ret
+}
+
+// Returns the last part of the hierarchical tag
+func GetTagName(tag string) (string) {
+    idx := strings.LastIndex(tag, TAG_SEPARATOR)
+    return tag[idx+1:]
+}
+
+// Sorts tags in tree order so that children follow their parent. Tags on the
+// same level are sorted in descending order if requested.
+func SortTags(tags []string, descending bool) {
+    sort.Slice(tags, func(i, j int) bool {
+        a := strings.Split(tags[i], TAG_SEPARATOR)
+        b := strings.Split(tags[j], TAG_SEPARATOR)
+        for k := 0; k < len(a) && k < len(b); k++ {
+            if a[k] != b[k] {
+                return (a[k] < b[k]) != descending
+            }
+        }
+        return len(a) < len(b)
+    })
+}
+
+// Returns count of notes for each tag and all of their parents. Note is
+// counted only once for each parent even if it has multiple tags under it.
+func (n *Notes) GetTagTree() (map[string]int) {
+    ret := map[string]int{}
+    for i := 0; i < len(n.notes); i++ {
+        note := &n.notes[i]
+        counted := map[string]bool{}
+        for _, tag := range note.Tags {
+            for _, t := range GetTagWithParents(n.config.CanonicalTag(tag)) {
+                if counted[t] {
+                    continue
+                }
+                counted[t] = true
+                ret[t] = ret[t] + 1
+            }
+        }
+    }
+    return ret
+}
+
+// Returns direct children of the tag in the hierarchy. Empty tag returns
+// the top level tags.
+func (n *Notes) GetChildTags(tag string) ([]string) {
+    var ret []string
+    for _, t := range n.GetTagKeys() {
+        if GetParentTag(t) == tag {
+            ret = append(ret, t)
+        }
+    }
+    return ret
+}
+
+// Returns an error if the tag would be moved under itself
+func checkTagTarget(source string, target string) (error) {
+    if strings.HasPrefix(target, source + TAG_SEPARATOR) {
+        return errors.New("Tag " + source + " can not be moved under itself to " + target)
+    }
+    return nil
+}
+
+// Renames the tag and all tags under it in every note. Returns the number
+// of notes modified.
+func (n *Notes) RenameTag(old string, new string) (int, error) {
+    err := checkTagTarget(old, new)
+    if err != nil {
+        return 0, err
+    }
+
+    count := 0
+    for i := 0; i < len(n.notes); i++ {
+        note := &n.notes[i]
+        var tags []string
+        modified := false
+        for _, tag := range note.Tags {
+            if TagMatches(tag, old) {
+                tag = new + tag[len(old):]
+                modified = true
+            }
+
+            if !containsString(tags, tag) {
+                tags = append(tags, tag)
+            }
+        }
+
+        if modified {
+            note.Tags = tags
+            count++
+        }
+    }
+    return count, nil
+}
+
+// Merges the source tags into one tag in every note. Returns the number of
+// notes modified.
+func (n *Notes) MergeTags(sources []string, into string) (int, error) {
+    for _, source := range sources {
+        err := checkTagTarget(source, into)
+        if err != nil {
+            return 0, err
+        }
+    }
+
+    modified := map[uint]bool{}
+    for _, source := range sources {
+        for i := 0; i < len(n.notes); i++ {
+            if n.notes[i].HasTag(source) {
+                modified[n.notes[i].Id] = true
+            }
+        }
+        n.RenameTag(source, into)
+    }
+    return len(modified), nil
+}
+
+// Deletes the tag and all tags under it from every note. Returns the number
+// of notes modified.
+func (n *Notes) DeleteTag(tag string) (int) {
+    count := 0
+    for i := 0; i < len(n.notes); i++ {
+        note := &n.notes[i]
+        var tags []string
+        for _, t := range note.Tags {
+            if !TagMatches(t, tag) {
+                tags = append(tags, t)
+            }
+        }
+
+        if len(tags) != len(note.Tags) {
+            note.Tags = tags
+            count++
+        }
+    }
+    return count
+}
+
+// Returns true if the note has the tag or any tag under it. Tag aliases
+// from the configuration are taken into account.
+func (n *Notes) NoteHasTag(note *Note, tag string) (bool) {
+    filter := n.config.CanonicalTag(tag)
+    for _, t := range note.Tags {
+        if TagMatches(n.config.CanonicalTag(t), filter) {
+            return true
+        }
+    }
+    return false
+}
+
+func containsString(list []string, str string) (bool) {
+    for _, s := range list {
+        if s == str {
+            return true
+        }
+    }
+    return false
+}
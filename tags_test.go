@@ -0,0 +1,90 @@
+package main
+
+import (
+    "reflect"
+    "testing"
+)
+
+func TestSortTags(t *testing.T) {
+    tags := []string{"work/backend", "home", "work-x", "work", "work/backend/api", "work/a"}
+
+    tests := []struct {
+        descending bool
+        expected []string
+    }{
+        {false, []string{"home", "work", "work/a", "work/backend", "work/backend/api", "work-x"}},
+        {true, []string{"work-x", "work", "work/backend", "work/backend/api", "work/a", "home"}},
+    }
+
+    for _, test := range tests {
+        sorted := append([]string(nil), tags...)
+        SortTags(sorted, test.descending)
+        if !reflect.DeepEqual(sorted, test.expected) {
+            t.Errorf("SortTags(%v) = %v, want %v", test.descending, sorted, test.expected)
+        }
+    }
+}
+
+func TestRenameTag(t *testing.T) {
+    tests := []struct {
+        old string
+        new string
+        expected []string
+        err bool
+    }{
+        {"work", "job", []string{"job", "job/all", "workshop"}, false},
+        {"work/all", "work", []string{"work", "workshop"}, false},
+        {"work", "workshop", []string{"workshop", "workshop/all"}, false},
+        {"work", "work/all", nil, true},
+        {"work", "work/a/b", nil, true},
+    }
+
+    for _, test := range tests {
+        n := newTestNotes(Note{Id: 1, Tags: []string{"work", "work/all", "workshop"}})
+        _, err := n.RenameTag(test.old, test.new)
+        if test.err {
+            if err == nil {
+                t.Errorf("RenameTag(%q, %q) should fail", test.old, test.new)
+            }
+            if !reflect.DeepEqual(n.notes[0].Tags, []string{"work", "work/all", "workshop"}) {
+                t.Errorf("RenameTag(%q, %q) modified tags to %v", test.old, test.new, n.notes[0].Tags)
+            }
+            continue
+        }
+        if err != nil {
+            t.Errorf("RenameTag(%q, %q) failed: %v", test.old, test.new, err)
+            continue
+        }
+        if !reflect.DeepEqual(n.notes[0].Tags, test.expected) {
+            t.Errorf("RenameTag(%q, %q) gave tags %v, want %v", test.old, test.new, n.notes[0].Tags, test.expected)
+        }
+    }
+}
+
+func TestMergeTags(t *testing.T) {
+    n := newTestNotes(
+        Note{Id: 1, Tags: []string{"bug"}},
+        Note{Id: 2, Tags: []string{"defect/ui"}},
+        Note{Id: 3, Tags: []string{"feature"}},
+    )
+
+    _, err := n.MergeTags([]string{"bug", "feature"}, "feature/all")
+    if err == nil {
+        t.Errorf("Merging tag under itself should fail")
+    }
+
+    count, err := n.MergeTags([]string{"bug", "defect"}, "issue")
+    if err != nil {
+        t.Fatal(err)
+    }
+    if count != 2 {
+        t.Errorf("MergeTags() modified %v notes, want 2", count)
+    }
+
+    expected := [][]string{{"issue"}, {"issue/ui"}, {"feature"}}
+    for i, tags := range expected {
+        if !reflect.DeepEqual(n.notes[i].Tags, tags) {
+            t.Errorf("Tags of note %v are %v, want %v", n.notes[i].Id, n.notes[i].Tags, tags)
+        }
+    }
+}
@@ -0,0 +1,411 @@
+package main
+
+import (
+    "errors"
+    "fmt"
+    "io/ioutil"
+    "os"
+    "path/filepath"
+    "regexp"
+    "sort"
+    "strconv"
+    "strings"
+
+    "github.com/jroimartin/gocui"
+    "github.com/fatih/color"
+)
+
+const TEMPLATE_EXTENSION = ".md"
+
+var templatePlaceholder = regexp.MustCompile(`\{\{\s*([a-zA-Z]+)(?::([^}]*))?\s*\}\}`)
+
+// Template for new notes. Templates are Markdown files in the templates
+// folder with optional header for tags, priority and due date, for example
+//
+//  ---
+//  tags: bug, backend
+//  prio: 4
+//  due: 3d
+//  ---
+//  Bug: {{prompt:Summary}}
+type Template struct {
+    Name string
+    Tags []string
+    Priority int
+    Due string
+    Content string
+}
+
+var defaultTemplates = map[string]string{
+    "bug": `---
+tags: bug
+prio: 4
+due: 3d
+---
+Bug: {{prompt:Summary}}
+
+Reported by {{user}} on {{date}}
+
+## Steps to reproduce
+
+1.
+
+## Expected behavior
+
+## Actual behavior
+
+## Environment
+`,
+    "meeting": `---
+tags: meeting
+---
+Meeting: {{prompt:Topic}} {{date}}
+
+Attendees: {{user}}
+
+## Agenda
+
+## Notes
+
+## Action items
+
+- [ ]
+`,
+    "release": `---
+tags: release
+prio: 3
+due: 1w
+---
+Release {{prompt:Version}}
+
+- [ ] Update changelog
+- [ ] Bump version number
+- [ ] Run tests
+- [ ] Tag the release {{prompt:Version}}
+- [ ] Build and publish binaries
+- [ ] Announce the release
+`,
+}
+
+// Returns the templates folder. Folder is created with the default templates
+// if it does not exist yet.
+func GetTemplateFolder() (string, error) {
+    app_folder, err := CreateAppFolder()
+    if err != nil {
+        return "", err
+    }
+
+    folder := app_folder + "/templates"
+    _, err = os.Stat(folder)
+    if err == nil {
+        return folder, nil
+    }
+    if !os.IsNotExist(err) {
+        return "", err
+    }
+
+    err = os.Mkdir(folder, 0770)
+    if err != nil {
+        return "", err
+    }
+
+    for name, content := range defaultTemplates {
+        err = ioutil.WriteFile(folder + "/" + name + TEMPLATE_EXTENSION, []byte(content), 0660)
+        if err != nil {
+            return "", err
+        }
+    }
+    return folder, nil
+}
+
+// Returns all templates ordered by name
+func LoadTemplates() ([]*Template, error) {
+    folder, err := GetTemplateFolder()
+    if err != nil {
+        return nil, err
+    }
+
+    files, err := ioutil.ReadDir(folder)
+    if err != nil {
+        return nil, err
+    }
+
+    var ret []*Template
+    for _, file := range files {
+        if file.IsDir() || filepath.Ext(file.Name()) != TEMPLATE_EXTENSION {
+            continue
+        }
+
+        data, err := ioutil.ReadFile(folder + "/" + file.Name())
+        if err != nil {
+            return nil, err
+        }
+
+        template, err := ParseTemplate(strings.TrimSuffix(file.Name(), TEMPLATE_EXTENSION), string(data))
+        if err != nil {
+            return nil, err
+        }
+        ret = append(ret, template)
+    }
+
+    sort.Slice(ret, func(i, j int) bool {
+        return ret[i].Name < ret[j].Name
+    })
+    return ret, nil
+}
+
+// Returns template with given name
+func FindTemplate(name string) (*Template, error) {
+    templates, err := LoadTemplates()
+    if err != nil {
+        return nil, err
+    }
+
+    for _, template := range templates {
+        if strings.EqualFold(template.Name, name) {
+            return template, nil
+        }
+    }
+    return nil, errors.New("Could not find template " + name + ". Use templates command to list them")
+}
+
+// Parses template with the optional header
+func ParseTemplate(name string, data string) (*Template, error) {
+    ret := &Template{Name: name, Priority: -1}
+    data = strings.Replace(data, "\r\n", "\n", -1)
+
+    if !strings.HasPrefix(data, "---\n") {
+        ret.Content = data
+        return ret, nil
+    }
+
+    rest := data[3:]
+    end := strings.Index(rest, "\n---")
+    if end < 0 {
+        return nil, errors.New("Header of template " + name + " is not closed with ---")
+    }
+
+    header := rest[:end]
+    ret.Content = strings.TrimPrefix(rest[end+4:], "\n")
+
+    for _, line := range strings.Split(header, "\n") {
+        if len(strings.Trim(line, " ")) == 0 {
+            continue
+        }
+
+        parts := strings.SplitN(line, ":", 2)
+        if len(parts) != 2 {
+            return nil, errors.New("Invalid header line \"" + line + "\" in template " + name)
+        }
+
+        value := strings.Trim(parts[1], " ")
+        switch(strings.ToLower(strings.Trim(parts[0], " "))) {
+            case "tags":
+                for _, tag := range strings.Split(value, ",") {
+                    tag = strings.Trim(tag, " ")
+                    if len(tag) > 0 {
+                        ret.Tags = append(ret.Tags, tag)
+                    }
+                }
+                break
+            case "prio":
+                prio, err := strconv.ParseUint(value, 0, 32)
+                if err != nil || prio > 5 {
+                    return nil, errors.New("Invalid priority in template " + name + ". Priority should be in range 0-5")
+                }
+                ret.Priority = int(prio)
+                break
+            case "due":
+                ret.Due = value
+                break
+            default:
+                return nil, errors.New("Unknown header \"" + parts[0] + "\" in template " + name + ". Use tags, prio or due")
+        }
+    }
+    return ret, nil
+}
+
+// Returns the labels of the prompted values in the order they appear in
+// the template. Each label is returned only once.
+func (t *Template) GetPrompts() ([]string) {
+    var ret []string
+    for _, match := range templatePlaceholder.FindAllStringSubmatch(t.Content, -1) {
+        if strings.ToLower(match[1]) != "prompt" {
+            continue
+        }
+
+        label := strings.Trim(match[2], " ")
+        found := false
+        for _, prompt := range ret {
+            found = found || prompt == label
+        }
+        if !found {
+            ret = append(ret, label)
+        }
+    }
+    return ret
+}
+
+// Returns new note from the template. Prompted values are taken from the
+// given values by their labels.
+func (n *Notes) ApplyTemplate(t *Template, values map[string]string) (Note, error) {
+    note := Note{Priority: n.config.DefaultPriority}
+    if t.Priority >= 0 {
+        note.Priority = uint(t.Priority)
+    }
+
+    if len(t.Due) > 0 {
+        due, err := ParseDate(t.Due, n.config.DueFormat)
+        if err != nil {
+            return note, errors.New("Invalid due in template " + t.Name + ". Use for example 3d, 1w or friday")
+        }
+        note.Due = due
+    }
+
+    for _, tag := range t.Tags {
+        note.AddTag(tag)
+    }
+
+    today := GetToday()
+    note.Content = templatePlaceholder.ReplaceAllStringFunc(t.Content, func(str string) string {
+        match := templatePlaceholder.FindStringSubmatch(str)
+        switch(strings.ToLower(match[1])) {
+            case "date":
+                return today.Format(n.config.DueFormat)
+            case "user":
+                return n.GetCurrentUser()
+            case "prompt":
+                return values[strings.Trim(match[2], " ")]
+        }
+        return str
+    })
+    return note, nil
+}
+
+const (
+    TEMPLATE_VIEW = "template"
+)
+
+func (n *NotesGui) setTemplateKeybindings(g *gocui.Gui) (error) {
+    bindings := map[interface{}]func(*gocui.Gui, *gocui.View) error {
+        'j': n.templateMove(1),
+        'k': n.templateMove(-1),
+        'q': n.closeTemplatePicker,
+        gocui.KeyArrowDown: n.templateMove(1),
+        gocui.KeyArrowUp: n.templateMove(-1),
+        gocui.KeyEnter: n.selectTemplate,
+    }
+
+    for key, f := range bindings {
+        err := g.SetKeybinding(TEMPLATE_VIEW, key, gocui.ModNone, f)
+        if err != nil {
+            return err
+        }
+    }
+    return nil
+}
+
+func (n *NotesGui) openTemplatePicker(g *gocui.Gui) error {
+    n.templatePickerShown = true
+    n.templateIdx = 0
+
+    err := n.layout(g)
+    if err != nil {
+        return err
+    }
+    return n.update(g)
+}
+
+func (n *NotesGui) closeTemplatePicker(g *gocui.Gui, v *gocui.View) error {
+    n.templatePickerShown = false
+
+    err := n.layout(g)
+    if err != nil {
+        return err
+    }
+    return n.update(g)
+}
+
+func (n *NotesGui) templateMove(delta int) (func(*gocui.Gui, *gocui.View) error) {
+    return func(g *gocui.Gui, v *gocui.View) error {
+        // First item of the picker is an empty note
+        n.templateIdx = (n.templateIdx + delta + len(n.templates) + 1) % (len(n.templates) + 1)
+        return n.update(g)
+    }
+}
+
+// Adds note from the selected template. Prompted values of the template
+// are asked in the command line before opening the editor.
+func (n *NotesGui) selectTemplate(g *gocui.Gui, v *gocui.View) error {
+    err := n.closeTemplatePicker(g, v)
+    if err != nil {
+        return err
+    }
+
+    if n.templateIdx == 0 {
+        return n.addNoteFromTemplate(g, nil, nil)
+    }
+
+    template := n.templates[n.templateIdx - 1]
+    if len(template.GetPrompts()) == 0 {
+        return n.addNoteFromTemplate(g, template, nil)
+    }
+
+    n.pendingTemplate = template
+    n.templateValues = map[string]string{}
+    n.cmd = n.getTemplatePrompt()
+    _, err = g.SetCurrentView(COMMAND_VIEW)
+    if err != nil {
+        return err
+    }
+    return n.update(g)
+}
+
+// Returns the command line prompt for the next value of the pending template
+func (n *NotesGui) getTemplatePrompt() (string) {
+    prompts := n.pendingTemplate.GetPrompts()
+    return prompts[len(n.templateValues)] + ": "
+}
+
+func (n *NotesGui) answerTemplatePrompt(g *gocui.Gui) error {
+    prompts := n.pendingTemplate.GetPrompts()
+    value := strings.TrimPrefix(n.cmd, n.getTemplatePrompt())
+    n.templateValues[prompts[len(n.templateValues)]] = value
+
+    if len(n.templateValues) < len(prompts) {
+        n.cmd = n.getTemplatePrompt()
+        return n.update(g)
+    }
+
+    template := n.pendingTemplate
+    n.pendingTemplate = nil
+    n.cmd = ""
+    _, err := g.SetCurrentView(n.mainView())
+    if err != nil {
+        return err
+    }
+    return n.addNoteFromTemplate(g, template, n.templateValues)
+}
+
+func (n *NotesGui) updateTemplateView(g *gocui.Gui) error {
+    v, err := g.View(TEMPLATE_VIEW)
+    if err != nil {
+        return err
+    }
+
+    v.Clear()
+    v.Title = "New note"
+    names := []string{"empty note"}
+    for _, template := range n.templates {
+        names = append(names, template.Name)
+    }
+
+    for i, name := range names {
+        c := color.New()
+        if i == n.templateIdx {
+            c.Add(color.ReverseVideo)
+        }
+        fmt.Fprintln(v, c.Sprint(" " + name + " "))
+    }
+    return nil
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+    "reflect"
+    "testing"
+)
+
+func TestParseTemplate(t *testing.T) {
+    tests := []struct {
+        data string
+        expected *Template
+        err bool
+    }{
+        {"Just content\n", &Template{Name: "t", Priority: -1, Content: "Just content\n"}, false},
+        {"---\ntags: bug, backend\nprio: 4\ndue: 3d\n---\nBug: {{prompt:Summary}}\n",
+            &Template{Name: "t", Tags: []string{"bug", "backend"}, Priority: 4, Due: "3d", Content: "Bug: {{prompt:Summary}}\n"}, false},
+        {"---\r\nprio: 0\r\n---\r\nWindows\r\n", &Template{Name: "t", Priority: 0, Content: "Windows\n"}, false},
+        {"---\n---\nEmpty header", &Template{Name: "t", Priority: -1, Content: "Empty header"}, false},
+        {"---\n\n Tags :  a ,, b \n---\n", &Template{Name: "t", Tags: []string{"a", "b"}, Priority: -1, Content: ""}, false},
+        {"---\ntags: bug\nNot closed", nil, true},
+        {"---\nprio: 6\n---\n", nil, true},
+        {"---\nprio: high\n---\n", nil, true},
+        {"---\nowner: me\n---\n", nil, true},
+        {"---\nno colon\n---\n", nil, true},
+    }
+
+    for _, test := range tests {
+        template, err := ParseTemplate("t", test.data)
+        if test.err {
+            if err == nil {
+                t.Errorf("ParseTemplate(%q) should fail", test.data)
+            }
+            continue
+        }
+        if err != nil {
+            t.Errorf("ParseTemplate(%q) failed: %v", test.data, err)
+            continue
+        }
+        if !reflect.DeepEqual(template, test.expected) {
+            t.Errorf("ParseTemplate(%q) = %+v, want %+v", test.data, template, test.expected)
+        }
+    }
+}
+
+func TestApplyTemplate(t *testing.T) {
+    n := newTestNotes()
+    n.config.UserName = "alice"
+    today := GetToday()
+    date := today.Format(n.config.DueFormat)
+
+    tests := []struct {
+        template Template
+        values map[string]string
+        content string
+        priority uint
+        tags []string
+        due string
+        err bool
+    }{
+        {Template{Name: "plain", Priority: -1, Content: "Plain"}, nil, "Plain", n.config.DefaultPriority, nil, "", false},
+        {Template{Name: "bug", Priority: 4, Tags: []string{"bug"}, Due: "3d", Content: "Bug: {{prompt:Summary}} by {{user}} on {{ date }}"},
+            map[string]string{"Summary": "Crash"}, "Bug: Crash by alice on " + date, 4, []string{"bug"},
+            today.AddDate(0, 0, 3).Format(n.config.DueFormat), false},
+        {Template{Name: "repeat", Priority: 0, Content: "{{prompt:V}} and {{prompt: V }}"},
+            map[string]string{"V": "1.0"}, "1.0 and 1.0", 0, nil, "", false},
+        {Template{Name: "missing", Priority: -1, Content: "[{{prompt:V}}] {{unknown}}"}, nil, "[] {{unknown}}", n.config.DefaultPriority, nil, "", false},
+        {Template{Name: "due", Priority: -1, Due: "someday"}, nil, "", 0, nil, "", true},
+    }
+
+    for _, test := range tests {
+        note, err := n.ApplyTemplate(&test.template, test.values)
+        if test.err {
+            if err == nil {
+                t.Errorf("ApplyTemplate(%v) should fail", test.template.Name)
+            }
+            continue
+        }
+        if err != nil {
+            t.Errorf("ApplyTemplate(%v) failed: %v", test.template.Name, err)
+            continue
+        }
+
+        due := ""
+        if !note.Due.IsZero() {
+            due = note.Due.Format(n.config.DueFormat)
+        }
+        if note.Content != test.content || note.Priority != test.priority || !reflect.DeepEqual(note.Tags, test.tags) || due != test.due {
+            t.Errorf("ApplyTemplate(%v) = %q prio %v tags %v due %q, want %q prio %v tags %v due %q", test.template.Name,
+                note.Content, note.Priority, note.Tags, due, test.content, test.priority, test.tags, test.due)
+        }
+    }
+}
+
+func TestGetPrompts(t *testing.T) {
+    template := Template{Content: "{{prompt:Version}} {{date}} {{prompt:Summary}} {{ prompt: Version }}"}
+    expected := []string{"Version", "Summary"}
+    if !reflect.DeepEqual(template.GetPrompts(), expected) {
+        t.Errorf("GetPrompts() = %v, want %v", template.GetPrompts(), expected)
+    }
+}
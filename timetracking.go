@@ -0,0 +1,164 @@
+package main
+
+import (
+    "errors"
+    "sort"
+    "strconv"
+    "strings"
+    "time"
+)
+
+// Time spent on a note. Running timers have no end time.
+type TimeEntry struct {
+    Author string `json:"author"`
+    Start time.Time `json:"start"`
+    End time.Time `json:"end"`
+}
+
+func (e *TimeEntry) IsRunning() (bool) {
+    return e.End.IsZero()
+}
+
+// Returns duration of the entry. Running entries last until now.
+func (e *TimeEntry) GetDuration() (time.Duration) {
+    if e.IsRunning() {
+        return time.Since(e.Start)
+    }
+    return e.End.Sub(e.Start)
+}
+
+// Returns duration of the entry that falls between given times
+func (e *TimeEntry) GetDurationBetween(since time.Time, until time.Time) (time.Duration) {
+    start := e.Start
+    end := e.End
+    if e.IsRunning() {
+        end = time.Now()
+    }
+
+    if start.Before(since) {
+        start = since
+    }
+    if !until.IsZero() && end.After(until) {
+        end = until
+    }
+
+    if end.Before(start) {
+        return 0
+    }
+    return end.Sub(start)
+}
+
+// Returns total time tracked for the note
+func (n *Note) GetTrackedTime() (time.Duration) {
+    var ret time.Duration
+    for i, _ := range n.TimeEntries {
+        ret += n.TimeEntries[i].GetDuration()
+    }
+    return ret
+}
+
+// Returns the note and the entry of the running timer of the current user
+func (n *Notes) GetRunningTimer() (*Note, *TimeEntry) {
+    user := n.GetCurrentUser()
+    for _, note := range n.GetNotes() {
+        for i, _ := range note.TimeEntries {
+            entry := &note.TimeEntries[i]
+            if entry.IsRunning() && strings.EqualFold(entry.Author, user) {
+                return note, entry
+            }
+        }
+    }
+    return nil, nil
+}
+
+// Starts timer for the note. Running timer of another note is stopped as
+// time can be tracked only for one note at a time.
+func (n *Notes) StartTimer(note *Note) (error) {
+    running, _ := n.GetRunningTimer()
+    if running != nil && running.Id == note.Id {
+        return errors.New("Timer is already running for the note")
+    }
+
+    n.StopTimer()
+    note.TimeEntries = append(note.TimeEntries, TimeEntry{Author: n.GetCurrentUser(), Start: time.Now()})
+    return nil
+}
+
+// Stops the running timer of the current user. Returns the note and the
+// entry of the stopped timer or nil if no timer was running.
+func (n *Notes) StopTimer() (*Note, *TimeEntry) {
+    note, entry := n.GetRunningTimer()
+    if entry != nil {
+        entry.End = time.Now()
+    }
+    return note, entry
+}
+
+// Returns time tracked for the notes between given times grouped by tag,
+// note, day or user. Notes with many tags are counted under each of them.
+// Only time tracked by the author is counted unless the author is empty.
+func (n *Notes) GetTimeReport(notes []*Note, since time.Time, until time.Time, by string, author string) ([]string, map[string]time.Duration, error) {
+    totals := map[string]time.Duration{}
+    for _, note := range notes {
+        for i, _ := range note.TimeEntries {
+            entry := &note.TimeEntries[i]
+            if len(author) > 0 && !strings.EqualFold(entry.Author, author) {
+                continue
+            }
+
+            d := entry.GetDurationBetween(since, until)
+            if d <= 0 {
+                continue
+            }
+
+            var keys []string
+            switch(by) {
+                case "tag":
+                    for _, tag := range note.Tags {
+                        keys = append(keys, n.config.CanonicalTag(tag))
+                    }
+                    if len(keys) == 0 {
+                        keys = append(keys, "(no tag)")
+                    }
+                    break
+                case "note":
+                    keys = append(keys, "#" + strconv.Itoa(int(note.Id)) + " " + note.GetTitle())
+                    break
+                case "day":
+                    keys = append(keys, entry.Start.Local().Format("2006-01-02"))
+                    break
+                case "user":
+                    keys = append(keys, entry.Author)
+                    break
+                default:
+                    return nil, nil, errors.New("Invalid grouping " + by + ". Use tag, note, day or user")
+            }
+
+            for _, key := range keys {
+                totals[key] += d
+            }
+        }
+    }
+
+    keys := make([]string, 0, len(totals))
+    for key := range totals {
+        keys = append(keys, key)
+    }
+    sort.Strings(keys)
+    return keys, totals, nil
+}
+
+// Returns time tracked for the notes between given times
+func (n *Notes) GetTotalTime(notes []*Note, since time.Time, until time.Time, author string) (time.Duration) {
+    var ret time.Duration
+    for _, note := range notes {
+        for i, _ := range note.TimeEntries {
+            entry := &note.TimeEntries[i]
+            if len(author) > 0 && !strings.EqualFold(entry.Author, author) {
+                continue
+            }
+            ret += entry.GetDurationBetween(since, until)
+        }
+    }
+    return ret
+}
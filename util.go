@@ -7,6 +7,7 @@ import (
     "errors"
     "fmt"
     "strings"
+    "strconv"
     "time"
     "golang.org/x/crypto/ssh/terminal"
 
@@ -96,3 +97,151 @@ func GetPriorityColor(n *Note) (*color.Color) {
 func RoundTimeToDay(t time.Time) time.Time {
     return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
 }
+
+// Returns the current date in the same form due dates are stored in. Dates
+// are stored in UTC as the timezone is stripped when the notes are saved.
+func GetToday() (time.Time) {
+    now := time.Now()
+    return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// Parses a date for due and scheduled dates. Supported formats are "today",
+// "tomorrow", weekdays such as "friday" meaning the next such day, days and
+// weeks from today such as "3d" and "2w" and dates in the given format.
+func ParseDate(str string, format string) (time.Time, error) {
+    today := GetToday()
+    lower := strings.ToLower(strings.Trim(str, " "))
+
+    switch(lower) {
+        case "today":
+            return today, nil
+        case "tomorrow":
+            return today.AddDate(0, 0, 1), nil
+    }
+
+    for i := 1; i <= 7; i++ {
+        day := today.AddDate(0, 0, i)
+        if strings.ToLower(day.Weekday().String()) == lower {
+            return day, nil
+        }
+    }
+
+    lower = strings.TrimPrefix(lower, "+")
+    if len(lower) > 1 {
+        amount, err := strconv.Atoi(lower[:len(lower)-1])
+        if err == nil && amount >= 0 {
+            switch(lower[len(lower)-1]) {
+                case 'd':
+                    return today.AddDate(0, 0, amount), nil
+                case 'w':
+                    return today.AddDate(0, 0, 7 * amount), nil
+            }
+        }
+    }
+
+    date, err := time.Parse(format, strings.Trim(str, " "))
+    if err != nil {
+        return date, errors.New("Invalid date given. Use for example tomorrow, friday, 3d or date in format " + format)
+    }
+    return date, nil
+}
+
+// Parses a point of time in the past. Supported formats are relative
+// durations such as "30m", "12h", "7d" and "2w", "today", "yesterday",
+// weekdays such as "monday" and dates in the given format.
+func ParseSince(str string, format string) (time.Time, error) {
+    now := time.Now()
+    today := RoundTimeToDay(now)
+    str = strings.ToLower(strings.Trim(str, " "))
+
+    switch(str) {
+        case "today":
+            return today, nil
+        case "yesterday":
+            return today.AddDate(0, 0, -1), nil
+    }
+
+    // Weekdays refer to the latest such day
+    for i := 0; i < 7; i++ {
+        day := today.AddDate(0, 0, -i)
+        if strings.ToLower(day.Weekday().String()) == str {
+            return day, nil
+        }
+    }
+
+    if len(str) > 1 {
+        amount, err := strconv.Atoi(str[:len(str)-1])
+        if err == nil && amount >= 0 {
+            switch(str[len(str)-1]) {
+                case 'm':
+                    return now.Add(-time.Duration(amount) * time.Minute), nil
+                case 'h':
+                    return now.Add(-time.Duration(amount) * time.Hour), nil
+                case 'd':
+                    return today.AddDate(0, 0, -amount), nil
+                case 'w':
+                    return today.AddDate(0, 0, -7 * amount), nil
+            }
+        }
+    }
+
+    t, err := time.ParseInLocation(format, str, time.Local)
+    if err != nil {
+        return t, errors.New("Invalid time given. Use for example 7d, 12h, yesterday, monday or date in format " + format)
+    }
+    return t, nil
+}
+
+// Returns size in human readable format
+func FormatSize(size int64) (string) {
+    units := []string{"B", "KB", "MB", "GB"}
+    value := float64(size)
+    i := 0
+    for value >= 1024 && i < len(units) - 1 {
+        value /= 1024
+        i++
+    }
+
+    if i == 0 {
+        return strconv.FormatInt(size, 10) + " " + units[i]
+    }
+    return strconv.FormatFloat(value, 'f', 1, 64) + " " + units[i]
+}
+
+// Parses duration such as "30m", "2h", "1h30m", "3d" or "1w"
+func ParseDuration(str string) (time.Duration, error) {
+    str = strings.ToLower(strings.Trim(str, " "))
+    d, err := time.ParseDuration(str)
+    if err == nil && d >= 0 {
+        return d, nil
+    }
+
+    if len(str) > 1 {
+        amount, err := strconv.Atoi(str[:len(str)-1])
+        if err == nil && amount >= 0 {
+            switch(str[len(str)-1]) {
+                case 'd':
+                    return time.Duration(amount) * 24 * time.Hour, nil
+                case 'w':
+                    return time.Duration(amount) * 7 * 24 * time.Hour, nil
+            }
+        }
+    }
+    return 0, errors.New("Invalid duration given. Use for example 30m, 2h, 1h30m, 3d or 1w")
+}
+
+// Returns duration in short human readable format such as "1h30m" or "2d"
+func FormatDuration(d time.Duration) (string) {
+    if d % (24 * time.Hour) == 0 && d > 0 {
+        return strconv.Itoa(int(d / (24 * time.Hour))) + "d"
+    }
+
+    ret := d.String()
+    if strings.HasSuffix(ret, "m0s") {
+        ret = ret[:len(ret)-2]
+    }
+    if strings.HasSuffix(ret, "h0m") {
+        ret = ret[:len(ret)-2]
+    }
+    return ret
+}
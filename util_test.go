@@ -0,0 +1,132 @@
+package main
+
+import (
+    "testing"
+    "time"
+)
+
+func TestParseDuration(t *testing.T) {
+    tests := []struct {
+        str string
+        d time.Duration
+        err bool
+    }{
+        {"30m", 30 * time.Minute, false},
+        {"1h30m", 90 * time.Minute, false},
+        {" 2H ", 2 * time.Hour, false},
+        {"3d", 3 * 24 * time.Hour, false},
+        {"1w", 7 * 24 * time.Hour, false},
+        {"0m", 0, false},
+        {"-2h", 0, true},
+        {"-3d", 0, true},
+        {"d", 0, true},
+        {"soon", 0, true},
+    }
+
+    for _, test := range tests {
+        d, err := ParseDuration(test.str)
+        if test.err {
+            if err == nil {
+                t.Errorf("ParseDuration(%q) should fail", test.str)
+            }
+            continue
+        }
+        if err != nil {
+            t.Errorf("ParseDuration(%q) failed: %v", test.str, err)
+            continue
+        }
+        if d != test.d {
+            t.Errorf("ParseDuration(%q) = %v, want %v", test.str, d, test.d)
+        }
+    }
+}
+
+func TestParseSince(t *testing.T) {
+    now := time.Now()
+    today := RoundTimeToDay(now)
+    // Latest monday including today
+    monday := today
+    for monday.Weekday() != time.Monday {
+        monday = monday.AddDate(0, 0, -1)
+    }
+
+    tests := []struct {
+        str string
+        since time.Time
+        err bool
+    }{
+        {"today", today, false},
+        {" Yesterday ", today.AddDate(0, 0, -1), false},
+        {"monday", monday, false},
+        {"30m", now.Add(-30 * time.Minute), false},
+        {"12h", now.Add(-12 * time.Hour), false},
+        {"7d", today.AddDate(0, 0, -7), false},
+        {"2w", today.AddDate(0, 0, -14), false},
+        {"01.10.2026", time.Date(2026, 10, 1, 0, 0, 0, 0, time.Local), false},
+        {"-1d", time.Time{}, true},
+        {"someday", time.Time{}, true},
+    }
+
+    for _, test := range tests {
+        since, err := ParseSince(test.str, "02.01.2006")
+        if test.err {
+            if err == nil {
+                t.Errorf("ParseSince(%q) should fail", test.str)
+            }
+            continue
+        }
+        if err != nil {
+            t.Errorf("ParseSince(%q) failed: %v", test.str, err)
+            continue
+        }
+        // Relative times are counted from the time of the call
+        diff := since.Sub(test.since)
+        if diff < 0 || diff > time.Minute {
+            t.Errorf("ParseSince(%q) = %v, want %v", test.str, since, test.since)
+        }
+    }
+}
+
+func TestParseDate(t *testing.T) {
+    today := GetToday()
+    // Next friday after today
+    friday := today.AddDate(0, 0, 1)
+    for friday.Weekday() != time.Friday {
+        friday = friday.AddDate(0, 0, 1)
+    }
+
+    tests := []struct {
+        str string
+        date time.Time
+        err bool
+    }{
+        {"today", today, false},
+        {" Tomorrow ", today.AddDate(0, 0, 1), false},
+        {"friday", friday, false},
+        {"3d", today.AddDate(0, 0, 3), false},
+        {"+3d", today.AddDate(0, 0, 3), false},
+        {"2w", today.AddDate(0, 0, 14), false},
+        {"0d", today, false},
+        {"24.12.2026", time.Date(2026, 12, 24, 0, 0, 0, 0, time.UTC), false},
+        {"-3d", time.Time{}, true},
+        {"3x", time.Time{}, true},
+        {"someday", time.Time{}, true},
+    }
+
+    for _, test := range tests {
+        date, err := ParseDate(test.str, "02.01.2006")
+        if test.err {
+            if err == nil {
+                t.Errorf("ParseDate(%q) should fail", test.str)
+            }
+            continue
+        }
+        if err != nil {
+            t.Errorf("ParseDate(%q) failed: %v", test.str, err)
+            continue
+        }
+        if !date.Equal(test.date) {
+            t.Errorf("ParseDate(%q) = %v, want %v", test.str, date, test.date)
+        }
+    }
+}